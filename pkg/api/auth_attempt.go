@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+)
+
+type AuthAttempt = domain.AuthAttempt
+
+// AuthAttemptStore keeps the pending notion sign in attempts until their
+// callback arrives. Take removes the attempt it returns, so an attempt is used
+// at most once even when several replicas share the store.
+type AuthAttemptStore interface {
+	Put(ctx context.Context, attempt *AuthAttempt) error
+	Take(ctx context.Context, id string) (*AuthAttempt, bool, error)
+	DeleteExpired(ctx context.Context, before time.Time) (int, error)
+}
+
+type inMemoryAuthAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]*AuthAttempt
+}
+
+func NewMemoryAuthAttemptStore() *inMemoryAuthAttemptStore {
+	return &inMemoryAuthAttemptStore{
+		attempts: make(map[string]*AuthAttempt),
+	}
+}
+
+var _ AuthAttemptStore = (*inMemoryAuthAttemptStore)(nil)
+
+func (s *inMemoryAuthAttemptStore) Put(_ context.Context, attempt *AuthAttempt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *attempt
+	s.attempts[attempt.ID] = &copied
+	return nil
+}
+
+func (s *inMemoryAuthAttemptStore) Take(_ context.Context, id string) (*AuthAttempt, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attempt, ok := s.attempts[id]
+	delete(s.attempts, id)
+	return attempt, ok, nil
+}
+
+func (s *inMemoryAuthAttemptStore) DeleteExpired(_ context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deleted := 0
+	for id, attempt := range s.attempts {
+		if attempt.ExpiresAt.Before(before) {
+			delete(s.attempts, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
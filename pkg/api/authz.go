@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+)
+
+const (
+	ownerPathValue = "userID"
+	adminPath      = "/api/admin/"
+	apiKeysPath    = "/api-keys"
+	sessionPath    = "/api/session"
+)
+
+var (
+	ErrForbidden = NewError(
+		http.StatusForbidden,
+		WithMessage("the resource belongs to another user"),
+	)
+	ErrAdminOnly   = NewError(http.StatusForbidden, WithMessage("admin only"))
+	ErrSessionOnly = NewError(
+		http.StatusForbidden,
+		WithMessage("api keys cannot be used here, sign in instead"),
+	)
+	ErrInsufficientScope = NewError(
+		http.StatusForbidden,
+		WithMessage("the api key lacks the scope for this request"),
+	)
+)
+
+// AdminChecker reports whether the session may act on resources of any user.
+type AdminChecker func(ctx context.Context, session *Session) bool
+
+func denyAdmin(context.Context, *Session) bool { return false }
+
+// APIKeyAuthenticator returns the session an api key acts as. It reports false
+// for unknown or revoked keys and for keys of disabled accounts.
+type APIKeyAuthenticator func(ctx context.Context, key string) (*Session, bool, error)
+
+// SetAPIKeyAuthenticator enables api keys. Without it bearer tokens are refused.
+func (m *APIServeMux) SetAPIKeyAuthenticator(authenticate APIKeyAuthenticator) {
+	m.apiKeys = authenticate
+}
+
+// SetAdminChecker installs the check which lets administrators through the
+// ownership check of the {userID} routes and into the /api/admin routes.
+// Without one nobody is an admin.
+func (m *APIServeMux) SetAdminChecker(isAdmin AdminChecker) {
+	m.isAdmin = isAdmin
+}
+
+func ownedByPathUser(pattern string) bool {
+	return strings.Contains(pattern, "{"+ownerPathValue+"}")
+}
+
+func adminOnly(pattern string) bool {
+	return strings.HasPrefix(extractPath(pattern), adminPath)
+}
+
+// sessionOnly routes manage credentials or the whole service, so a leaked api
+// key must not reach them.
+func sessionOnly(pattern string) bool {
+	path := extractPath(pattern)
+	return adminOnly(pattern) ||
+		strings.HasPrefix(path, sessionPath) ||
+		strings.Contains(path, apiKeysPath)
+}
+
+// authorize wraps the handler of pattern with the checks its path calls for.
+func (m *APIServeMux) authorize(pattern string, handlerFn HandlerFunc) HandlerFunc {
+	switch {
+	case adminOnly(pattern):
+		handlerFn = m.requireAdmin(handlerFn)
+	case ownedByPathUser(pattern):
+		handlerFn = m.requireOwner(handlerFn)
+	}
+
+	return requireScope(sessionOnly(pattern), handlerFn)
+}
+
+// admin never holds for api keys; administration needs a login session.
+func (m *APIServeMux) admin(ctx context.Context, session *Session) bool {
+	return session.APIKey == nil && m.isAdmin(ctx, session)
+}
+
+// requireScope limits requests made with an api key: reading needs the read
+// scope, anything else the write scope.
+func requireScope(sessionOnly bool, handlerFn HandlerFunc) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		session, ok := r.Context().Value(SessionKey{}).(*Session)
+		if !ok || session.APIKey == nil {
+			return handlerFn(w, r)
+		}
+
+		if sessionOnly {
+			return ErrSessionOnly
+		}
+
+		scope := domain.ScopeWrite
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			scope = domain.ScopeRead
+		}
+		if !session.APIKey.HasScope(scope) {
+			return ErrInsufficientScope
+		}
+
+		return handlerFn(w, r)
+	}
+}
+
+// requireOwner only lets the request through when the {userID} path value is
+// the user of the session, or the session belongs to an administrator.
+func (m *APIServeMux) requireOwner(handlerFn HandlerFunc) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		session, ok := r.Context().Value(SessionKey{}).(*Session)
+		if !ok {
+			return ErrNoSession
+		}
+
+		userID, err := uuid.Parse(r.PathValue(ownerPathValue))
+		if err != nil {
+			return NewError(http.StatusBadRequest, WithError(err))
+		}
+
+		if session.UserID != userID && !m.admin(r.Context(), session) {
+			return ErrForbidden
+		}
+
+		return handlerFn(w, r)
+	}
+}
+
+func (m *APIServeMux) requireAdmin(handlerFn HandlerFunc) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		session, ok := r.Context().Value(SessionKey{}).(*Session)
+		if !ok {
+			return ErrNoSession
+		}
+
+		if !m.admin(r.Context(), session) {
+			return ErrAdminOnly
+		}
+
+		return handlerFn(w, r)
+	}
+}
@@ -0,0 +1,211 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+)
+
+var authzTestPatterns = []string{
+	"GET /api/users/{userID}",
+	"PUT /api/users/{userID}",
+	"DELETE /api/users/{userID}",
+	"GET /api/users/{userID}/notion",
+	"POST /api/users/{userID}/notion",
+	"GET /api/users/{userID}/notion/{notionPageID}",
+	"PUT /api/users/{userID}/notion/{notionPageID}",
+	"DELETE /api/users/{userID}/notion/{notionPageID}",
+	"GET /api/users/{userID}/mindmap",
+	"PUT /api/users/{userID}/mindmap",
+	"DELETE /api/users/{userID}/mindmap",
+	"GET /api/users/{userID}/api-keys",
+	"GET /api/admin/users",
+	"GET /api/sessions",
+}
+
+type authzTestServer struct {
+	mux     *APIServeMux
+	store   *inMemoryStore
+	admins  map[uuid.UUID]bool
+	apiKeys map[string]*domain.APIKey
+}
+
+func newAuthzTestServer(t *testing.T) *authzTestServer {
+	t.Helper()
+
+	manager, store, _ := newTestSessionManager(t)
+	s := &authzTestServer{
+		mux:     newAPIServeMux(manager, "*"),
+		store:   store,
+		admins:  make(map[uuid.UUID]bool),
+		apiKeys: make(map[string]*domain.APIKey),
+	}
+	s.mux.SetAdminChecker(func(_ context.Context, session *Session) bool {
+		return s.admins[session.UserID]
+	})
+	s.mux.SetAPIKeyAuthenticator(func(_ context.Context, key string) (*Session, bool, error) {
+		apiKey, ok := s.apiKeys[key]
+		if !ok {
+			return nil, false, nil
+		}
+		return &Session{UserID: apiKey.UserID, APIKey: apiKey}, true, nil
+	})
+
+	for _, pattern := range authzTestPatterns {
+		s.mux.RegistAPI(NewSimpleAPI(pattern, func(w http.ResponseWriter, r *http.Request) error {
+			w.WriteHeader(http.StatusOK)
+			return nil
+		}))
+	}
+
+	return s
+}
+
+// login stores a session of userID and returns its cookie.
+func (s *authzTestServer) login(t *testing.T, userID uuid.UUID) *http.Cookie {
+	t.Helper()
+
+	id := uuid.NewString()
+	now := s.mux.sessions.now()
+	err := s.store.Set(context.Background(), &Session{
+		ID:         id,
+		UserID:     userID,
+		CreatedAt:  now,
+		LastSeenAt: now,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &http.Cookie{Name: SessionCookieName, Value: id}
+}
+
+func (s *authzTestServer) issueKey(userID uuid.UUID, scopes ...string) string {
+	key := domain.APIKeyPrefix + uuid.NewString()
+	s.apiKeys[key] = &domain.APIKey{ID: uuid.New(), UserID: userID, Scopes: scopes}
+	return key
+}
+
+func (s *authzTestServer) do(method, path string, auth func(r *http.Request)) int {
+	r := httptest.NewRequest(method, path, nil)
+	auth(r)
+	w := httptest.NewRecorder()
+	s.mux.ServeHTTP(w, r)
+
+	return w.Code
+}
+
+func withCookie(cookie *http.Cookie) func(r *http.Request) {
+	return func(r *http.Request) {
+		r.AddCookie(cookie)
+	}
+}
+
+func withAPIKey(key string) func(r *http.Request) {
+	return func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer "+key)
+	}
+}
+
+func ownedRequests(userID uuid.UUID) []struct{ method, path string } {
+	user := "/api/users/" + userID.String()
+	page := user + "/notion/" + uuid.NewString()
+
+	return []struct{ method, path string }{
+		{http.MethodGet, user},
+		{http.MethodPut, user},
+		{http.MethodDelete, user},
+		{http.MethodGet, user + "/notion"},
+		{http.MethodPost, user + "/notion"},
+		{http.MethodGet, page},
+		{http.MethodPut, page},
+		{http.MethodDelete, page},
+		{http.MethodGet, user + "/mindmap"},
+		{http.MethodPut, user + "/mindmap"},
+		{http.MethodDelete, user + "/mindmap"},
+	}
+}
+
+func TestAuthorizeOwner(t *testing.T) {
+	s := newAuthzTestServer(t)
+	alice, bob := uuid.New(), uuid.New()
+	aliceCookie := s.login(t, alice)
+
+	for _, req := range ownedRequests(bob) {
+		if code := s.do(req.method, req.path, withCookie(aliceCookie)); code != http.StatusForbidden {
+			t.Errorf("%s %s of another user = %d, want %d", req.method, req.path, code, http.StatusForbidden)
+		}
+	}
+	for _, req := range ownedRequests(alice) {
+		if code := s.do(req.method, req.path, withCookie(aliceCookie)); code != http.StatusOK {
+			t.Errorf("%s %s of the own user = %d, want %d", req.method, req.path, code, http.StatusOK)
+		}
+	}
+}
+
+func TestAuthorizeAdmin(t *testing.T) {
+	s := newAuthzTestServer(t)
+	admin, bob := uuid.New(), uuid.New()
+	s.admins[admin] = true
+	adminCookie := s.login(t, admin)
+	bobCookie := s.login(t, bob)
+
+	for _, req := range ownedRequests(bob) {
+		if code := s.do(req.method, req.path, withCookie(adminCookie)); code != http.StatusOK {
+			t.Errorf("%s %s by an admin = %d, want %d", req.method, req.path, code, http.StatusOK)
+		}
+	}
+
+	if code := s.do(http.MethodGet, "/api/admin/users", withCookie(adminCookie)); code != http.StatusOK {
+		t.Errorf("admin route by an admin = %d, want %d", code, http.StatusOK)
+	}
+	if code := s.do(http.MethodGet, "/api/admin/users", withCookie(bobCookie)); code != http.StatusForbidden {
+		t.Errorf("admin route by a user = %d, want %d", code, http.StatusForbidden)
+	}
+}
+
+func TestAuthorizeAPIKey(t *testing.T) {
+	s := newAuthzTestServer(t)
+	alice, bob, admin := uuid.New(), uuid.New(), uuid.New()
+	s.admins[admin] = true
+	aliceUser := "/api/users/" + alice.String()
+
+	readKey := s.issueKey(alice, domain.ScopeRead)
+	writeKey := s.issueKey(alice, domain.ScopeWrite)
+	adminKey := s.issueKey(admin, domain.ScopeRead, domain.ScopeWrite)
+
+	tests := []struct {
+		name   string
+		key    string
+		method string
+		path   string
+		want   int
+	}{
+		{"read with read scope", readKey, http.MethodGet, aliceUser + "/mindmap", http.StatusOK},
+		{"write with read scope", readKey, http.MethodPut, aliceUser + "/mindmap", http.StatusForbidden},
+		{"delete with read scope", readKey, http.MethodDelete, aliceUser, http.StatusForbidden},
+		{"write with write scope", writeKey, http.MethodPost, aliceUser + "/notion", http.StatusOK},
+		{"read with write scope", writeKey, http.MethodGet, aliceUser + "/notion", http.StatusForbidden},
+		{"another user", writeKey, http.MethodPut, "/api/users/" + bob.String(), http.StatusForbidden},
+		{"api key route", readKey, http.MethodGet, aliceUser + "/api-keys", http.StatusForbidden},
+		{"session route", readKey, http.MethodGet, "/api/sessions", http.StatusForbidden},
+		{"admin route", adminKey, http.MethodGet, "/api/admin/users", http.StatusForbidden},
+		{"admin override", adminKey, http.MethodGet, "/api/users/" + bob.String(), http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := s.do(tt.method, tt.path, withAPIKey(tt.key)); code != tt.want {
+				t.Errorf("%s %s = %d, want %d", tt.method, tt.path, code, tt.want)
+			}
+		})
+	}
+
+	if code := s.do(http.MethodGet, aliceUser, withAPIKey("nmk_unknown")); code != http.StatusUnauthorized {
+		t.Errorf("unknown api key = %d, want %d", code, http.StatusUnauthorized)
+	}
+}
@@ -17,6 +17,7 @@ var (
 	)
 	ErrInvalidSession = NewError(http.StatusUnauthorized, WithMessage("invalid session"))
 	ErrNoSession      = NewError(http.StatusUnauthorized, WithMessage("no session"))
+	ErrInvalidAPIKey  = NewError(http.StatusUnauthorized, WithMessage("invalid api key"))
 )
 
 type Error struct {
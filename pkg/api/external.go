@@ -0,0 +1,112 @@
+package api
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/config"
+)
+
+// ExternalURL builds the absolute URLs under which clients reach the server,
+// which differ from what the server sees when it runs behind a reverse proxy.
+type ExternalURL struct {
+	publicURL *url.URL
+	trusted   []*net.IPNet
+}
+
+func NewExternalURL(cfg *config.ServerConfig) (*ExternalURL, error) {
+	e := &ExternalURL{}
+
+	if cfg.PublicURL != "" {
+		u, err := url.Parse(strings.TrimSuffix(cfg.PublicURL, "/"))
+		if err != nil {
+			return nil, err
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("public url must be an absolute http(s) url: %s", cfg.PublicURL)
+		}
+		e.publicURL = u
+	}
+
+	for _, proxy := range cfg.TrustedProxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy: %s", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				bits = 8 * net.IPv4len
+			}
+			proxy = fmt.Sprintf("%s/%d", proxy, bits)
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy: %s", proxy)
+		}
+		e.trusted = append(e.trusted, network)
+	}
+
+	return e, nil
+}
+
+// Resolve returns the absolute URL of path as seen by the client of r. The
+// configured public url wins; otherwise the request decides, including its
+// X-Forwarded-Proto and X-Forwarded-Host headers if a trusted proxy sent it.
+func (e *ExternalURL) Resolve(r *http.Request, path string) string {
+	if e.publicURL != nil {
+		return e.publicURL.String() + path
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	host := r.Host
+
+	if e.fromTrustedProxy(r) {
+		if proto := forwardedValue(r.Header.Get("X-Forwarded-Proto")); proto == "http" || proto == "https" {
+			scheme = proto
+		}
+		if fwdHost := forwardedValue(r.Header.Get("X-Forwarded-Host")); fwdHost != "" {
+			host = fwdHost
+		}
+	}
+
+	return scheme + "://" + host + path
+}
+
+func (e *ExternalURL) fromTrustedProxy(r *http.Request) bool {
+	if len(e.trusted) == 0 {
+		return false
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range e.trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// forwardedValue takes the last entry of a header proxies may have appended
+// to. It was set by the trusted proxy itself, while the ones before it may
+// come from the client.
+func forwardedValue(header string) string {
+	values := strings.Split(header, ",")
+	return strings.ToLower(strings.TrimSpace(values[len(values)-1]))
+}
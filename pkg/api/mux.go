@@ -11,12 +11,19 @@ import (
 )
 
 type APIServeMux struct {
-	mux *http.ServeMux
+	mux           *http.ServeMux
+	sessions      *SessionManager
+	isAdmin       AdminChecker
+	apiKeys       APIKeyAuthenticator
+	allowedOrigin string
 }
 
-func newAPIServeMux() *APIServeMux {
+func newAPIServeMux(sessions *SessionManager, allowedOrigin string) *APIServeMux {
 	return &APIServeMux{
-		mux: http.NewServeMux(),
+		mux:           http.NewServeMux(),
+		sessions:      sessions,
+		isAdmin:       denyAdmin,
+		allowedOrigin: allowedOrigin,
 	}
 }
 
@@ -27,7 +34,7 @@ type (
 
 func (m *APIServeMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// CORS 헤더 설정 (모든 요청에 적용)
-	w.Header().Set("Access-Control-Allow-Origin", "http://localhost:3000")
+	w.Header().Set("Access-Control-Allow-Origin", m.allowedOrigin)
 	w.Header().Set("Access-Control-Allow-Credentials", "true")
 	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
@@ -55,19 +62,17 @@ func (m *APIServeMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := context.WithValue(r.Context(), RequestIDKey{}, requestID)
 
 	if !strings.HasPrefix(path, "/api") {
-		m.mux.ServeHTTP(w, r)
+		m.mux.ServeHTTP(w, r.WithContext(ctx))
 		return
 	}
 
-	cookie, err := r.Cookie("sessionID")
+	session, err := m.authenticate(ctx, r)
 	if err != nil {
-		NewError(http.StatusUnauthorized, WithError(err)).WriteHTTPError(w)
-		return
-	}
-
-	session, ok := SessionStore.Get(cookie.Value)
-	if !ok {
-		ErrNoSession.WriteHTTPError(w)
+		apiError := Error{}
+		if !errors.As(err, &apiError) {
+			apiError = NewError(http.StatusInternalServerError, WithError(err))
+		}
+		apiError.WriteHTTPError(w)
 		return
 	}
 
@@ -77,9 +82,43 @@ func (m *APIServeMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	m.mux.ServeHTTP(w, r)
 }
 
+// authenticate finds the session of the request from its api key, given as
+// "Authorization: Bearer <key>", or else from its session cookie.
+func (m *APIServeMux) authenticate(ctx context.Context, r *http.Request) (*Session, error) {
+	if key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		if m.apiKeys == nil {
+			return nil, ErrInvalidAPIKey
+		}
+
+		session, ok, err := m.apiKeys(ctx, strings.TrimSpace(key))
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrInvalidAPIKey
+		}
+		return session, nil
+	}
+
+	cookie, err := r.Cookie(SessionCookieName)
+	if err != nil {
+		return nil, NewError(http.StatusUnauthorized, WithError(err))
+	}
+
+	session, ok, err := m.sessions.Load(ctx, cookie.Value)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNoSession
+	}
+
+	return session, nil
+}
+
 func (m *APIServeMux) RegistAPI(apis ...*API) {
 	for _, a := range apis {
-		m.mux.HandleFunc(a.Pattern, WithErrorHandler(a.Handler))
+		m.mux.HandleFunc(a.Pattern, WithErrorHandler(m.authorize(a.Pattern, a.Handler)))
 	}
 }
 
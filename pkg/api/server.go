@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"syscall"
@@ -15,9 +16,23 @@ import (
 
 type Server struct {
 	httpServer *http.Server
+	sessions   *SessionManager
+	workers    []func(ctx context.Context)
 }
 
-func NewServer(cfg *config.ServerConfig) (*Server, error) {
+func NewServer(
+	cfg *config.ServerConfig,
+	frontend *config.FrontendConfig,
+	sessions *SessionManager,
+) (*Server, error) {
+	frontendURL, err := url.Parse(frontend.URL)
+	if err != nil {
+		return nil, err
+	}
+	if frontendURL.Scheme == "" || frontendURL.Host == "" {
+		return nil, fmt.Errorf("frontend url must be absolute: %s", frontend.URL)
+	}
+
 	readTimeout, err := time.ParseDuration(cfg.ReadTimeout)
 	if err != nil {
 		return nil, err
@@ -38,17 +53,25 @@ func NewServer(cfg *config.ServerConfig) (*Server, error) {
 		ReadTimeout:  readTimeout,
 		WriteTimeout: writeTimeout,
 		IdleTimeout:  idleTimeout,
-		Handler:      newAPIServeMux(),
+		Handler:      newAPIServeMux(sessions, frontendURL.Scheme+"://"+frontendURL.Host),
 	}
 
 	server := &Server{
 		httpServer: httpSrv,
+		sessions:   sessions,
 	}
 
 	return server, nil
 }
 
 func (s *Server) Start() error {
+	sweepCtx, stopSweeper := context.WithCancel(context.Background())
+	defer stopSweeper()
+	go s.sessions.RunSweeper(sweepCtx)
+	for _, worker := range s.workers {
+		go worker(sweepCtx)
+	}
+
 	go func() {
 		log.Printf("Starting server on %s", s.httpServer.Addr)
 		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -74,6 +97,20 @@ func (s *Server) Start() error {
 	return nil
 }
 
+// AddWorker runs worker in the background while the server is up. Its context
+// is cancelled when the server shuts down.
+func (s *Server) AddWorker(worker func(ctx context.Context)) {
+	s.workers = append(s.workers, worker)
+}
+
+func (s *Server) SetAdminChecker(isAdmin AdminChecker) {
+	s.httpServer.Handler.(*APIServeMux).SetAdminChecker(isAdmin)
+}
+
+func (s *Server) SetAPIKeyAuthenticator(authenticate APIKeyAuthenticator) {
+	s.httpServer.Handler.(*APIServeMux).SetAPIKeyAuthenticator(authenticate)
+}
+
 func (s *Server) InstallAPIGroup(apigroups ...APIGroup) {
 	mux := s.httpServer.Handler.(*APIServeMux)
 
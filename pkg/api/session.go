@@ -1,65 +1,264 @@
 package api
 
 import (
-	"sync"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/config"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+)
+
+const (
+	SessionCookieName = "sessionID"
+
+	SessionStoreMemory  = "memory"
+	SessionStoreStorage = "storage"
+
+	// sessionTouchInterval keeps persistent stores from being written on every
+	// request just to move the idle expiry forward.
+	sessionTouchInterval = time.Minute
 )
 
-// NotionUserID를 key로 session 저장
-var SessionStore = &inMemoryStore{
-	store: make(map[string]*Session, 0),
-}
-
-// ***********************************
-// TEST
-// ***********************************
-// var SessionStore = &inMemoryStore{
-// 	store: map[string]*Session{
-// 		"3aeac03f-0645-4d10-a334-a5905c031c12": {
-// 			UserID:       uuid.MustParse("3aeac03f-0645-4d10-a334-a5905c031c12"),
-// 			NotionUserID: uuid.MustParse("97811864-e95f-41ee-8faf-e5dfce7d0326"),
-// 			Token: &Token{
-// 				AccessToken:   "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.eyJzdWIiOiIxMjM0NTY3ODkwIiwibmFtZSI6IkpvaG4gRG9lI",
-// 				RefreshToken:  "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.eyJzdWIiOiIxMjM0NTY3ODkwIiwibmFtZSI6IkpvaG4gRG9lI",
-// 				BotID:         "3399b2c7-a7ad-4778-a803-08262e4ed808",
-// 				WorkspaceName: "test",
-// 			},
-// 		},
-// 	},
-// }
-
-type inMemoryStore struct {
-	mu    sync.RWMutex
-	store map[string]*Session
-}
-
-func (s *inMemoryStore) Get(key string) (*Session, bool) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	session, ok := s.store[key]
-	if !ok {
-		return nil, false
-	}
-	copied := *session
-	return &copied, ok
-}
-
-func (s *inMemoryStore) Set(key string, session *Session) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.store[key] = session
-}
-
-type Session struct {
-	UserID       uuid.UUID `json:"user_id"`
-	NotionUserID uuid.UUID `json:"notion_user_id"`
-	Token        *Token    `json:"token"`
-}
-
-type Token struct {
-	AccessToken   string `json:"access_token"`
-	RefreshToken  string `json:"refresh_token"`
-	BotID         string `json:"bot_id"`
-	WorkspaceName string `json:"workspace_name"`
+type (
+	Session = domain.Session
+	Token   = domain.Token
+)
+
+// SessionStore persists sessions by their random ID. Expiry is decided by the
+// SessionManager, so a store only has to keep the timestamps.
+type SessionStore interface {
+	Get(ctx context.Context, id string) (*Session, bool, error)
+	Set(ctx context.Context, session *Session) error
+	Delete(ctx context.Context, id string) error
+	Touch(ctx context.Context, id string, at time.Time) error
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*Session, error)
+	DeleteByUser(ctx context.Context, userID uuid.UUID) (int, error)
+	// DeleteExpired removes the sessions created before createdBefore or last
+	// seen before idleBefore. A zero time disables that condition.
+	DeleteExpired(ctx context.Context, createdBefore, idleBefore time.Time) (int, error)
+}
+
+type SessionPolicy struct {
+	MaxAge        time.Duration
+	IdleTimeout   time.Duration
+	SweepInterval time.Duration
+	Secure        bool
+	SameSite      http.SameSite
+}
+
+func NewSessionPolicy(cfg *config.SessionConfig) (*SessionPolicy, error) {
+	policy := &SessionPolicy{
+		Secure: cfg.Secure,
+	}
+
+	var err error
+	if policy.MaxAge, err = time.ParseDuration(cfg.MaxAge); err != nil {
+		return nil, err
+	}
+	if policy.IdleTimeout, err = time.ParseDuration(cfg.IdleTimeout); err != nil {
+		return nil, err
+	}
+	if policy.SweepInterval, err = time.ParseDuration(cfg.SweepInterval); err != nil {
+		return nil, err
+	}
+	if policy.SweepInterval <= 0 {
+		return nil, fmt.Errorf("session sweep interval must be positive: %s", cfg.SweepInterval)
+	}
+
+	switch strings.ToLower(cfg.SameSite) {
+	case "", "lax":
+		policy.SameSite = http.SameSiteLaxMode
+	case "strict":
+		policy.SameSite = http.SameSiteStrictMode
+	case "none":
+		if !cfg.Secure {
+			return nil, errors.New("sameSite none requires secure session cookies")
+		}
+		policy.SameSite = http.SameSiteNoneMode
+	default:
+		return nil, fmt.Errorf("unknown sameSite mode: %s", cfg.SameSite)
+	}
+
+	return policy, nil
+}
+
+// expired reports whether the session outlived the absolute or idle timeout.
+func (p *SessionPolicy) expired(session *Session, now time.Time) bool {
+	if p.MaxAge > 0 && now.After(session.CreatedAt.Add(p.MaxAge)) {
+		return true
+	}
+	if p.IdleTimeout > 0 && now.After(session.LastSeenAt.Add(p.IdleTimeout)) {
+		return true
+	}
+
+	return false
+}
+
+func (p *SessionPolicy) cookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   p.Secure,
+		SameSite: p.SameSite,
+	}
+}
+
+// SessionManager applies a SessionPolicy on top of a SessionStore.
+type SessionManager struct {
+	store  SessionStore
+	policy *SessionPolicy
+	now    func() time.Time
+}
+
+func NewSessionManager(store SessionStore, policy *SessionPolicy) *SessionManager {
+	return &SessionManager{
+		store:  store,
+		policy: policy,
+		now:    time.Now,
+	}
+}
+
+// Start stores the session of the client of r under a new random ID and sets
+// the session cookie.
+func (m *SessionManager) Start(w http.ResponseWriter, r *http.Request, session *Session) error {
+	id, err := newSessionID()
+	if err != nil {
+		return err
+	}
+
+	now := m.now()
+	session.ID = id
+	session.CreatedAt = now
+	session.LastSeenAt = now
+	session.UserAgent = r.UserAgent()
+	if err := m.store.Set(r.Context(), session); err != nil {
+		return err
+	}
+
+	http.SetCookie(w, m.policy.cookie(id, int(m.policy.MaxAge.Seconds())))
+	return nil
+}
+
+// Load returns the session unless it does not exist or has expired, and
+// records the activity which postpones its idle expiry.
+func (m *SessionManager) Load(ctx context.Context, id string) (*Session, bool, error) {
+	session, ok, err := m.store.Get(ctx, id)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	now := m.now()
+	if m.policy.expired(session, now) {
+		return nil, false, m.store.Delete(ctx, id)
+	}
+
+	if now.Sub(session.LastSeenAt) >= sessionTouchInterval {
+		if err := m.store.Touch(ctx, id, now); err != nil {
+			return nil, false, err
+		}
+		session.LastSeenAt = now
+	}
+
+	return session, true, nil
+}
+
+func (m *SessionManager) Save(ctx context.Context, session *Session) error {
+	return m.store.Set(ctx, session)
+}
+
+func (m *SessionManager) Delete(ctx context.Context, id string) error {
+	return m.store.Delete(ctx, id)
+}
+
+func (m *SessionManager) ListByUser(ctx context.Context, userID uuid.UUID) ([]*Session, error) {
+	return m.store.ListByUser(ctx, userID)
+}
+
+func (m *SessionManager) DeleteByUser(ctx context.Context, userID uuid.UUID) (int, error) {
+	return m.store.DeleteByUser(ctx, userID)
+}
+
+// UpdateToken replaces the notion token held by every session of the user.
+func (m *SessionManager) UpdateToken(ctx context.Context, userID uuid.UUID, token *Token) error {
+	sessions, err := m.store.ListByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		copied := *token
+		session.Token = &copied
+		if err := m.store.Set(ctx, session); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SecureCookies reports whether cookies are only sent over https.
+func (m *SessionManager) SecureCookies() bool {
+	return m.policy.Secure
+}
+
+func (m *SessionManager) ClearCookie(w http.ResponseWriter) {
+	http.SetCookie(w, m.policy.cookie("", -1))
+}
+
+// Sweep evicts the expired sessions and returns how many there were.
+func (m *SessionManager) Sweep(ctx context.Context) (int, error) {
+	now := m.now()
+
+	var createdBefore, idleBefore time.Time
+	if m.policy.MaxAge > 0 {
+		createdBefore = now.Add(-m.policy.MaxAge)
+	}
+	if m.policy.IdleTimeout > 0 {
+		idleBefore = now.Add(-m.policy.IdleTimeout)
+	}
+
+	return m.store.DeleteExpired(ctx, createdBefore, idleBefore)
+}
+
+// RunSweeper sweeps the store every SweepInterval until ctx is done.
+func (m *SessionManager) RunSweeper(ctx context.Context) {
+	ticker := time.NewTicker(m.policy.SweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			swept, err := m.Sweep(ctx)
+			if err != nil {
+				log.Printf("fail to sweep expired sessions: %v", err)
+				continue
+			}
+			if swept > 0 {
+				log.Printf("swept %d expired sessions", swept)
+			}
+		}
+	}
+}
+
+func newSessionID() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
 }
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type inMemoryStore struct {
+	mu    sync.RWMutex
+	store map[string]*Session
+}
+
+func NewMemorySessionStore() *inMemoryStore {
+	return &inMemoryStore{
+		store: make(map[string]*Session, 0),
+	}
+}
+
+var _ SessionStore = (*inMemoryStore)(nil)
+
+func (s *inMemoryStore) Get(_ context.Context, id string) (*Session, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	session, ok := s.store[id]
+	if !ok {
+		return nil, false, nil
+	}
+	copied := *session
+	return &copied, true, nil
+}
+
+func (s *inMemoryStore) Set(_ context.Context, session *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *session
+	s.store[session.ID] = &copied
+	return nil
+}
+
+func (s *inMemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.store, id)
+	return nil
+}
+
+func (s *inMemoryStore) Touch(_ context.Context, id string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if session, ok := s.store[id]; ok {
+		session.LastSeenAt = at
+	}
+	return nil
+}
+
+func (s *inMemoryStore) ListByUser(_ context.Context, userID uuid.UUID) ([]*Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sessions := make([]*Session, 0)
+	for _, session := range s.store {
+		if session.UserID == userID {
+			copied := *session
+			sessions = append(sessions, &copied)
+		}
+	}
+	return sessions, nil
+}
+
+func (s *inMemoryStore) DeleteByUser(_ context.Context, userID uuid.UUID) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deleted := 0
+	for id, session := range s.store {
+		if session.UserID == userID {
+			delete(s.store, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (s *inMemoryStore) DeleteExpired(
+	_ context.Context,
+	createdBefore, idleBefore time.Time,
+) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deleted := 0
+	for id, session := range s.store {
+		if session.CreatedAt.Before(createdBefore) || session.LastSeenAt.Before(idleBefore) {
+			delete(s.store, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// fakeClock is a clock the tests move forward by hand.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestSessionManager(t *testing.T) (*SessionManager, *inMemoryStore, *fakeClock) {
+	t.Helper()
+
+	store := NewMemorySessionStore()
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	manager := NewSessionManager(store, &SessionPolicy{
+		MaxAge:        24 * time.Hour,
+		IdleTimeout:   time.Hour,
+		SweepInterval: time.Minute,
+		SameSite:      http.SameSiteLaxMode,
+	})
+	manager.now = clock.Now
+
+	return manager, store, clock
+}
+
+func startTestSession(t *testing.T, manager *SessionManager) string {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	if err := manager.Start(w, r, &Session{UserID: uuid.New()}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == SessionCookieName {
+			return cookie.Value
+		}
+	}
+	t.Fatal("no session cookie was set")
+	return ""
+}
+
+func TestSessionManagerIdleExpiry(t *testing.T) {
+	ctx := context.Background()
+	manager, store, clock := newTestSessionManager(t)
+	id := startTestSession(t, manager)
+
+	clock.Advance(time.Hour + time.Second)
+
+	if _, ok, err := manager.Load(ctx, id); err != nil || ok {
+		t.Fatalf("Load of an idle session = %t, %v, want rejected", ok, err)
+	}
+	if _, ok, _ := store.Get(ctx, id); ok {
+		t.Fatal("rejected idle session is still stored")
+	}
+}
+
+func TestSessionManagerMaxAgeExpiry(t *testing.T) {
+	ctx := context.Background()
+	manager, _, clock := newTestSessionManager(t)
+	id := startTestSession(t, manager)
+
+	// Stay active so only the absolute expiry applies.
+	for elapsed := time.Duration(0); elapsed < 24*time.Hour; elapsed += 30 * time.Minute {
+		clock.Advance(30 * time.Minute)
+		if _, ok, err := manager.Load(ctx, id); err != nil || !ok {
+			t.Fatalf("Load after %s = %t, %v, want an active session", elapsed, ok, err)
+		}
+	}
+
+	clock.Advance(time.Second)
+	if _, ok, err := manager.Load(ctx, id); err != nil || ok {
+		t.Fatalf("Load past the max age = %t, %v, want rejected", ok, err)
+	}
+}
+
+func TestSessionManagerTouchExtendsIdleExpiry(t *testing.T) {
+	ctx := context.Background()
+	manager, store, clock := newTestSessionManager(t)
+	id := startTestSession(t, manager)
+
+	clock.Advance(50 * time.Minute)
+	session, ok, err := manager.Load(ctx, id)
+	if err != nil || !ok {
+		t.Fatalf("Load = %t, %v, want an active session", ok, err)
+	}
+	if !session.LastSeenAt.Equal(clock.Now()) {
+		t.Fatalf("LastSeenAt = %s, want %s", session.LastSeenAt, clock.Now())
+	}
+	stored, _, _ := store.Get(ctx, id)
+	if !stored.LastSeenAt.Equal(clock.Now()) {
+		t.Fatalf("stored LastSeenAt = %s, want %s", stored.LastSeenAt, clock.Now())
+	}
+
+	// 100 minutes after the start, but only 50 after the last activity.
+	clock.Advance(50 * time.Minute)
+	if _, ok, err := manager.Load(ctx, id); err != nil || !ok {
+		t.Fatalf("Load after a touch = %t, %v, want an active session", ok, err)
+	}
+}
+
+func TestSessionManagerSweep(t *testing.T) {
+	ctx := context.Background()
+	manager, store, clock := newTestSessionManager(t)
+
+	old := startTestSession(t, manager)
+	clock.Advance(23*time.Hour + 30*time.Minute)
+	if err := store.Touch(ctx, old, clock.Now()); err != nil {
+		t.Fatal(err)
+	}
+	idle := startTestSession(t, manager)
+	clock.Advance(50 * time.Minute)
+	active := startTestSession(t, manager)
+	if err := store.Touch(ctx, idle, clock.Now().Add(-61*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	swept, err := manager.Sweep(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if swept != 2 {
+		t.Fatalf("swept %d sessions, want 2", swept)
+	}
+
+	for id, want := range map[string]bool{old: false, idle: false, active: true} {
+		if _, ok, _ := store.Get(ctx, id); ok != want {
+			t.Errorf("session stored = %t after sweep, want %t", ok, want)
+		}
+	}
+}
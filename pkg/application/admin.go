@@ -0,0 +1,70 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/repository"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/service"
+)
+
+// getAdminCommand bootstraps admins, since the admin API can only be reached
+// by a user who already is one.
+func (a *cli) getAdminCommand(configPath *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "admin",
+		Short: "Grant or revoke the admin role",
+	}
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "grant USER_ID",
+			Short: "Give a user the admin role",
+			Args:  cobra.ExactArgs(1),
+			RunE:  a.withRole(configPath, domain.RoleAdmin),
+		},
+		&cobra.Command{
+			Use:   "revoke USER_ID",
+			Short: "Take the admin role away from a user",
+			Args:  cobra.ExactArgs(1),
+			RunE:  a.withRole(configPath, domain.RoleUser),
+		},
+	)
+
+	return cmd
+}
+
+func (a *cli) withRole(configPath *string, role string) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		id, err := uuid.Parse(args[0])
+		if err != nil {
+			return err
+		}
+
+		cfg, err := a.loadConfig(*configPath)
+		if err != nil {
+			return err
+		}
+		if cfg.Storage.Driver == "" || cfg.Storage.Driver == repository.DriverMemory {
+			return errors.New("memory storage is not persistent, configure postgres or bolt to manage roles")
+		}
+
+		repos, err := repository.New(cmd.Context(), cfg.Storage, cfg.DB, cfg.Encryption)
+		if err != nil {
+			return err
+		}
+		defer repos.Close()
+
+		svc := service.NewUserService(repos.User, repos.NotionPage, repos.MindMap, repos.APIKey, repos.UnitOfWork)
+		if err := svc.SetRole(cmd.Context(), id, role); err != nil {
+			return err
+		}
+
+		fmt.Printf("user %s now has role %s\n", id, role)
+		return nil
+	}
+}
@@ -0,0 +1,132 @@
+package application
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/repository"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/service"
+)
+
+func (a *cli) getBackupCommand(configPath *string) *cobra.Command {
+	var out string
+
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Dump the whole dataset to a file",
+		Long: `Dump every user, notion page, keyword and api key of the configured storage
+backend to a JSON lines file with a version header and a sha256 checksum. The notion
+tokens are written decrypted so the backup can be restored under other keys; keep it
+private.`,
+		Args: cobra.NoArgs,
+		RunE: a.withBackupService(configPath, func(cmd *cobra.Command, svc *service.BackupService, driver string) error {
+			tmp := out + ".tmp"
+			f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+			if err != nil {
+				return err
+			}
+			defer os.Remove(tmp)
+
+			report, err := svc.Backup(cmd.Context(), f, driver)
+			if err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+			if err := os.Rename(tmp, out); err != nil {
+				return err
+			}
+
+			printBackupReport("backed up", report)
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVarP(&out, "out", "o", "", "Path of the backup file to write")
+	_ = cmd.MarkFlagRequired("out")
+
+	return cmd
+}
+
+func (a *cli) getRestoreCommand(configPath *string) *cobra.Command {
+	var in string
+
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Load a backup into an empty storage backend",
+		Long: `Verify a file written by the backup command and load it into the configured
+storage backend, which must not hold any data yet.`,
+		Args: cobra.NoArgs,
+		RunE: a.withBackupService(configPath, func(cmd *cobra.Command, svc *service.BackupService, driver string) error {
+			f, err := os.Open(in)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			report, err := svc.Restore(cmd.Context(), f)
+			if err != nil {
+				return err
+			}
+
+			printBackupReport("restored", report)
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVarP(&in, "in", "i", "", "Path of the backup file to read")
+	_ = cmd.MarkFlagRequired("in")
+
+	return cmd
+}
+
+func (a *cli) withBackupService(
+	configPath *string,
+	fn func(cmd *cobra.Command, svc *service.BackupService, driver string) error,
+) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		cfg, err := a.loadConfig(*configPath)
+		if err != nil {
+			return err
+		}
+
+		// Memory storage starts empty in every process, so there is nothing to
+		// back up and a restore would be lost on exit.
+		driver := cfg.Storage.Driver
+		if driver == "" || driver == repository.DriverMemory {
+			return fmt.Errorf("memory storage is not persistent, configure postgres or bolt to run %s", cmd.Name())
+		}
+
+		repos, err := repository.New(cmd.Context(), cfg.Storage, cfg.DB, cfg.Encryption)
+		if err != nil {
+			return err
+		}
+		defer repos.Close()
+
+		svc := service.NewBackupService(
+			repos.User,
+			repos.NotionPage,
+			repos.MindMap,
+			repos.APIKey,
+			repos.UnitOfWork,
+		)
+		return fn(cmd, svc, driver)
+	}
+}
+
+func printBackupReport(action string, report *domain.BackupReport) {
+	fmt.Printf(
+		"%s %d users, %d notion pages, %d keyword nodes, %d keyword edges, %d api keys\n",
+		action,
+		report.Users,
+		report.NotionPages,
+		report.KeywordNodes,
+		report.KeywordEdges,
+		report.APIKeys,
+	)
+}
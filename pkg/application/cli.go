@@ -1,15 +1,19 @@
 package application
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/api"
 	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/config"
 	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/controller"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/notion"
 	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/repository"
 	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/service"
 )
@@ -34,10 +38,17 @@ func (a *cli) Execute() error {
 		RunE:  a.getRunErrorFn(&configPath),
 	}
 
-	cmd.Flags().
+	cmd.PersistentFlags().
 		StringVarP(&configPath, "config", "c", "config/config.json", "Path to the configuration file")
 
-	cmd.AddCommand(a.getVersionCommand())
+	cmd.AddCommand(
+		a.getVersionCommand(),
+		a.getMigrateCommand(&configPath),
+		a.getBackupCommand(&configPath),
+		a.getRestoreCommand(&configPath),
+		a.getAdminCommand(&configPath),
+		a.getReencryptCommand(&configPath),
+	)
 
 	return cmd.Execute()
 }
@@ -54,38 +65,153 @@ func (a *cli) execute(configPath string) error {
 		return err
 	}
 
-	server, err := api.NewServer(cfg.Server)
+	repos, err := repository.New(context.Background(), cfg.Storage, cfg.DB, cfg.Encryption)
 	if err != nil {
 		return err
 	}
-	userRepo := repository.NewMemoryUserRepo()
-	userSvc := service.NewUserService(userRepo)
-	userAPIGroup := controller.NewUserController(userSvc)
+	defer repos.Close()
 
-	authAPIGroup, err := controller.NewAuthController(userSvc, cfg.OAuth)
+	sessions, err := newSessionManager(cfg.Session, repos)
+	if err != nil {
+		return err
+	}
+	authAttempts, err := newAuthAttemptStore(cfg.Session, repos)
 	if err != nil {
 		return err
 	}
 
-	mindMapRepo := repository.NewMemoryMindMapRepo()
-	mindMapSvc := service.NewMindMapService(mindMapRepo)
+	server, err := api.NewServer(cfg.Server, cfg.Frontend, sessions)
+	if err != nil {
+		return err
+	}
+
+	userSvc := service.NewUserService(repos.User, repos.NotionPage, repos.MindMap, repos.APIKey, repos.UnitOfWork)
+	userAPIGroup := controller.NewUserController(userSvc, sessions)
+
+	client, err := notion.NewClient(cfg.Notion, cfg.OAuth)
+	if err != nil {
+		return err
+	}
+	notionClient := controller.NewNotionClient(client)
+	tokens := service.NewTokenManager(userSvc, notionClient, sessions)
+	external, err := api.NewExternalURL(cfg.Server)
+	if err != nil {
+		return err
+	}
+	authAPIGroup, err := controller.NewAuthController(
+		userSvc,
+		sessions,
+		tokens,
+		notionClient,
+		external,
+		authAttempts,
+		cfg.OAuth,
+		cfg.Frontend,
+	)
+	if err != nil {
+		return err
+	}
+
+	sessionAPIGroup := controller.NewSessionController(sessions)
+	adminAPIGroup := controller.NewAdminController(userSvc, sessions)
+
+	server.SetAdminChecker(func(ctx context.Context, session *api.Session) bool {
+		return userSvc.IsAdmin(ctx, session.UserID)
+	})
+
+	apiKeySvc := service.NewAPIKeyService(repos.APIKey, repos.User, repos.UnitOfWork)
+	apiKeyAPIGroup := controller.NewAPIKeyController(apiKeySvc)
+
+	server.SetAPIKeyAuthenticator(apiKeySvc.Authenticate)
+
+	mindMapSvc := service.NewMindMapService(repos.MindMap, repos.UnitOfWork)
 	mindMapAPIGroup := controller.NewMindMapController(mindMapSvc)
 
-	notionPageRepo := repository.NewMemoryNotionPageRepo()
-	notionPageSvc := service.NewNotionPageService(notionPageRepo)
-	notionPageAPIGroup := controller.NewNotionPageController(notionPageSvc)
+	notionPageSvc := service.NewNotionPageService(repos.NotionPage)
+	notionSyncSvc := service.NewNotionSyncService(repos.NotionPage, userSvc, mindMapSvc, notionClient, tokens)
+	notionPageAPIGroup := controller.NewNotionPageController(notionPageSvc, notionSyncSvc)
+
+	syncInterval, err := time.ParseDuration(cfg.Notion.SyncInterval)
+	if err != nil {
+		return err
+	}
+	if syncInterval > 0 {
+		server.AddWorker(func(ctx context.Context) {
+			notionSyncSvc.RunScheduler(ctx, syncInterval)
+		})
+	}
+	server.AddWorker(notionSyncSvc.RunQueue)
+
+	webhookSvc := service.NewNotionWebhookService(repos.User, notionSyncSvc)
+	webhookAPIGroup := controller.NewWebhookController(webhookSvc, cfg.Notion)
+
+	archiveSvc := service.NewArchiveService(
+		repos.User,
+		repos.NotionPage,
+		repos.MindMap,
+		notionPageSvc,
+		mindMapSvc,
+		repos.UnitOfWork,
+	)
+	archiveAPIGroup := controller.NewArchiveController(archiveSvc)
 
 	server.InstallAPIGroup(
 		api.NewSimpleAPI("GET /version", a.getVersionHandler()),
 		mindMapAPIGroup,
 		userAPIGroup,
 		authAPIGroup,
+		sessionAPIGroup,
+		adminAPIGroup,
+		apiKeyAPIGroup,
 		notionPageAPIGroup,
+		archiveAPIGroup,
+		webhookAPIGroup,
 	)
 
 	return server.Start()
 }
 
+func newSessionManager(
+	cfg *config.SessionConfig,
+	repos *repository.Repositories,
+) (*api.SessionManager, error) {
+	policy, err := api.NewSessionPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.Store {
+	case "", api.SessionStoreMemory:
+		return api.NewSessionManager(api.NewMemorySessionStore(), policy), nil
+	case api.SessionStoreStorage:
+		if repos.Sessions == nil {
+			return nil, errors.New("session store \"storage\" requires a persistent storage driver")
+		}
+		return api.NewSessionManager(repos.Sessions, policy), nil
+	default:
+		return nil, fmt.Errorf("unknown session store: %s", cfg.Store)
+	}
+}
+
+// newAuthAttemptStore keeps the pending sign in attempts next to the sessions,
+// so a sign in works across replicas whenever the sessions do.
+func newAuthAttemptStore(
+	cfg *config.SessionConfig,
+	repos *repository.Repositories,
+) (api.AuthAttemptStore, error) {
+	switch cfg.Store {
+	case "", api.SessionStoreMemory:
+		return api.NewMemoryAuthAttemptStore(), nil
+	case api.SessionStoreStorage:
+		if repos.AuthAttempts == nil {
+			return nil, errors.New("session store \"storage\" requires a persistent storage driver")
+		}
+		return repos.AuthAttempts, nil
+	default:
+		return nil, fmt.Errorf("unknown session store: %s", cfg.Store)
+	}
+}
+
 func (a *cli) loadConfig(cfgFilePath string) (*config.AppConfig, error) {
 	cfg := config.Default()
 	if err := cfg.LoadConfig(cfgFilePath); err != nil {
@@ -0,0 +1,47 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/repository"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/service"
+)
+
+func (a *cli) getReencryptCommand(configPath *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "reencrypt",
+		Short: "Re-encrypt the stored notion tokens under the current key",
+		Long: `Rewrite the notion tokens of every user and session so they are encrypted with
+TOKEN_ENCRYPTION_KEY. To rotate the key, move the current key to
+TOKEN_ENCRYPTION_OLD_KEYS, set a new TOKEN_ENCRYPTION_KEY and run this command;
+afterwards the old key is no longer needed.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := a.loadConfig(*configPath)
+			if err != nil {
+				return err
+			}
+			if cfg.Storage.Driver == "" || cfg.Storage.Driver == repository.DriverMemory {
+				return errors.New("memory storage is not persistent, configure postgres or bolt to re-encrypt")
+			}
+
+			repos, err := repository.New(cmd.Context(), cfg.Storage, cfg.DB, cfg.Encryption)
+			if err != nil {
+				return err
+			}
+			defer repos.Close()
+
+			svc := service.NewEncryptionService(repos.User, repos.Sessions, repos.Cipher, repos.UnitOfWork)
+			report, err := svc.Reencrypt(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("re-encrypted %d users, %d sessions\n", report.Users, report.Sessions)
+			return nil
+		},
+	}
+}
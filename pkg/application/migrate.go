@@ -0,0 +1,102 @@
+package application
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/migration"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/repository"
+)
+
+func (a *cli) getMigrateCommand(configPath *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Manage the database schema of notion-mindmap-server",
+		Long:  `Apply, revert and inspect the versioned schema migrations of the postgres storage backend.`,
+	}
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "up",
+			Short: "Apply all pending migrations",
+			Args:  cobra.NoArgs,
+			RunE: a.withMigrator(configPath, func(cmd *cobra.Command, m *migration.Migrator, _ []string) error {
+				applied, err := m.Up(cmd.Context())
+				for _, mig := range applied {
+					fmt.Printf("applied %04d_%s\n", mig.Version, mig.Name)
+				}
+				if err != nil {
+					return err
+				}
+				if len(applied) == 0 {
+					fmt.Println("no pending migrations")
+				}
+				return nil
+			}),
+		},
+		&cobra.Command{
+			Use:   "down N",
+			Short: "Revert the latest N applied migrations",
+			Args:  cobra.ExactArgs(1),
+			RunE: a.withMigrator(configPath, func(cmd *cobra.Command, m *migration.Migrator, args []string) error {
+				n, err := strconv.Atoi(args[0])
+				if err != nil || n < 1 {
+					return fmt.Errorf("invalid number of migrations: %s", args[0])
+				}
+				reverted, err := m.Down(cmd.Context(), n)
+				for _, mig := range reverted {
+					fmt.Printf("reverted %04d_%s\n", mig.Version, mig.Name)
+				}
+				return err
+			}),
+		},
+		&cobra.Command{
+			Use:   "status",
+			Short: "Print the applied and pending migrations",
+			Args:  cobra.NoArgs,
+			RunE: a.withMigrator(configPath, func(cmd *cobra.Command, m *migration.Migrator, _ []string) error {
+				statuses, err := m.Status(cmd.Context())
+				if err != nil {
+					return err
+				}
+				for _, s := range statuses {
+					appliedAt := "pending"
+					if s.Applied() {
+						appliedAt = s.AppliedAt.Format("2006-01-02T15:04:05Z07:00")
+					}
+					fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, appliedAt)
+				}
+				return nil
+			}),
+		},
+	)
+
+	return cmd
+}
+
+func (a *cli) withMigrator(
+	configPath *string,
+	fn func(cmd *cobra.Command, m *migration.Migrator, args []string) error,
+) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		cfg, err := a.loadConfig(*configPath)
+		if err != nil {
+			return err
+		}
+
+		db, err := repository.NewPostgresDB(cfg.DB)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		m, err := migration.NewMigrator(db)
+		if err != nil {
+			return err
+		}
+
+		return fn(cmd, m, args)
+	}
+}
@@ -1,19 +1,22 @@
 package config
 
 import (
-	"crypto/rand"
 	"encoding/json"
-	"fmt"
 	"os"
 
 	"github.com/caarlos0/env/v11"
 )
 
 type AppConfig struct {
-	Server *ServerConfig `json:"server,omitempty"`
-	Log    *LogConfig    `json:"log,omitempty"`
-	OAuth  *OAuthConfig  `json:"-"`
-	DB     *DBConfig     `json:"-"`
+	Server     *ServerConfig     `json:"server,omitempty"`
+	Log        *LogConfig        `json:"log,omitempty"`
+	Storage    *StorageConfig    `json:"storage,omitempty"`
+	Session    *SessionConfig    `json:"session,omitempty"`
+	Frontend   *FrontendConfig   `json:"frontend,omitempty"`
+	Notion     *NotionConfig     `json:"notion,omitempty"`
+	OAuth      *OAuthConfig      `json:"-"`
+	DB         *DBConfig         `json:"-"`
+	Encryption *EncryptionConfig `json:"-"`
 }
 
 type ServerConfig struct {
@@ -22,17 +25,77 @@ type ServerConfig struct {
 	ReadTimeout  string `json:"readTimeout,omitempty"`
 	WriteTimeout string `json:"writeTimeout,omitempty"`
 	IdleTimeout  string `json:"idleTimeout,omitempty"`
+	// PublicURL is the https://host[:port] clients reach the server at. When
+	// empty it is derived from every request.
+	PublicURL string `json:"publicURL,omitempty"`
+	// TrustedProxies lists the IPs or CIDRs of the reverse proxies whose
+	// X-Forwarded-Proto and X-Forwarded-Host headers are honored.
+	TrustedProxies []string `json:"trustedProxies,omitempty"`
 }
 
 type LogConfig struct {
 	Level string `json:"level,omitempty"`
 }
 
+type StorageConfig struct {
+	Driver string `json:"driver,omitempty"`
+	Path   string `json:"path,omitempty"`
+	// RequireMigrated makes the server refuse to start when the postgres
+	// schema is behind instead of migrating it on startup.
+	RequireMigrated bool `json:"requireMigrated,omitempty"`
+}
+
+type SessionConfig struct {
+	// Store is "memory", or "storage" to keep sessions in the storage backend
+	// so they survive restarts and are shared between replicas.
+	Store string `json:"store,omitempty"`
+	// MaxAge is the absolute lifetime of a session and IdleTimeout how long it
+	// survives without requests.
+	MaxAge        string `json:"maxAge,omitempty"`
+	IdleTimeout   string `json:"idleTimeout,omitempty"`
+	SweepInterval string `json:"sweepInterval,omitempty"`
+	Secure        bool   `json:"secure"`
+	SameSite      string `json:"sameSite,omitempty"`
+}
+
+type FrontendConfig struct {
+	// URL is where the frontend is served. Users land there after signing in
+	// and it is the only origin allowed to call the api from a browser.
+	URL string `json:"url,omitempty"`
+	// AllowedRedirects are further origins a sign in may return to.
+	AllowedRedirects []string `json:"allowedRedirects,omitempty"`
+}
+
+type NotionConfig struct {
+	// BaseURL is where the notion api is served, replaced to talk to a fake.
+	BaseURL string `json:"baseURL,omitempty"`
+	// Version is sent as the Notion-Version header of every request.
+	Version string `json:"version,omitempty"`
+	// Timeout bounds every request to notion.
+	Timeout string `json:"timeout,omitempty"`
+	// SyncInterval is how often the pages of every connected user are synced
+	// in the background. "0" turns the background sync off.
+	SyncInterval string `json:"syncInterval,omitempty"`
+	// WebhookSecret is the verification token of the webhook subscription,
+	// which signs its deliveries. Webhooks are refused without it.
+	WebhookSecret string `json:"-" env:"NOTION_WEBHOOK_SECRET"`
+}
+
 type OAuthConfig struct {
 	ClientID     string `env:"OAUTH_CLIENT_ID"`
 	ClientSecret string `env:"OAUTH_CLIENT_SECRET"`
 	AuthURL      string `env:"AUTH_URL"`
-	State        string `env:"STATE"`
+}
+
+// EncryptionConfig holds the keys which encrypt the stored notion tokens. A key
+// is written as "<id>:<base64 of 32 random bytes>". Tokens are encrypted with
+// Key; OldKeys only decrypt tokens written before the last key rotation.
+type EncryptionConfig struct {
+	Key     string   `env:"TOKEN_ENCRYPTION_KEY"`
+	OldKeys []string `env:"TOKEN_ENCRYPTION_OLD_KEYS" envSeparator:","`
+	// AllowPlaintextTokens lets the persistent drivers start without Key and
+	// store the tokens in plaintext.
+	AllowPlaintextTokens bool `env:"ALLOW_PLAINTEXT_TOKENS"`
 }
 
 // TODO: Database 구성 검토 필요
@@ -59,20 +122,46 @@ func Default() *AppConfig {
 		Level: "info",
 	}
 
-	buf := make([]byte, 16)
-	if _, err := rand.Read(buf); err != nil {
-		buf = []byte("random-state-string")
+	storage := &StorageConfig{
+		Driver: "memory",
+	}
+
+	session := &SessionConfig{
+		Store:         "memory",
+		MaxAge:        "168h",
+		IdleTimeout:   "24h",
+		SweepInterval: "1m",
+		Secure:        true,
+		SameSite:      "lax",
+	}
+
+	frontend := &FrontendConfig{
+		URL: "http://localhost:3000",
+	}
+
+	notion := &NotionConfig{
+		BaseURL:      "https://api.notion.com",
+		Version:      "2022-06-28",
+		Timeout:      "30s",
+		SyncInterval: "30m",
 	}
+
 	oauth := &OAuthConfig{
-		State: fmt.Sprintf("%x", buf),
+		AuthURL: "https://api.notion.com/v1/oauth/authorize",
 	}
 	db := &DBConfig{}
+	encryption := &EncryptionConfig{}
 
 	return &AppConfig{
-		Server: server,
-		Log:    log,
-		OAuth:  oauth,
-		DB:     db,
+		Server:     server,
+		Log:        log,
+		Storage:    storage,
+		Session:    session,
+		Frontend:   frontend,
+		Notion:     notion,
+		OAuth:      oauth,
+		DB:         db,
+		Encryption: encryption,
 	}
 }
 
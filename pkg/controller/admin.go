@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/api"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/service"
+)
+
+type adminController struct {
+	service  *service.UserService
+	sessions *api.SessionManager
+}
+
+func NewAdminController(service *service.UserService, sessions *api.SessionManager) *adminController {
+	return &adminController{
+		service:  service,
+		sessions: sessions,
+	}
+}
+
+var _ api.APIGroup = (*adminController)(nil)
+
+// The /api/admin routes are only served to admins, see api.SetAdminChecker.
+func (c *adminController) ListAPIs() []*api.API {
+	return []*api.API{
+		api.NewSimpleAPI("GET /api/admin/users", c.listUsers),
+		api.NewSimpleAPI("GET /api/admin/users/{userID}/usage", c.getUsage),
+		api.NewSimpleAPI("PUT /api/admin/users/{userID}/role", c.setRole),
+		api.NewSimpleAPI("POST /api/admin/users/{userID}/disable", c.disableUser),
+		api.NewSimpleAPI("POST /api/admin/users/{userID}/enable", c.enableUser),
+		api.NewSimpleAPI("DELETE /api/admin/users/{userID}/sessions", c.deleteSessions),
+	}
+}
+
+func (c *adminController) listUsers(w http.ResponseWriter, r *http.Request) error {
+	users, err := c.service.ListUsers(r.Context())
+	if err != nil {
+		return api.NewError(http.StatusInternalServerError, api.WithError(err))
+	}
+
+	accounts := make([]*domain.UserAccount, 0, len(users))
+	for _, u := range users {
+		accounts = append(accounts, u.Account())
+	}
+
+	return api.ResponseJSON(r.Context(), w, accounts)
+}
+
+func (c *adminController) getUsage(w http.ResponseWriter, r *http.Request) error {
+	userUID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		return api.NewError(http.StatusBadRequest, api.WithError(err))
+	}
+
+	usage, err := c.service.GetUsage(r.Context(), userUID)
+	if err != nil {
+		return api.NewError(http.StatusInternalServerError, api.WithError(err))
+	}
+
+	sessions, err := c.sessions.ListByUser(r.Context(), userUID)
+	if err != nil {
+		return api.NewError(http.StatusInternalServerError, api.WithError(err))
+	}
+	usage.Sessions = len(sessions)
+
+	return api.ResponseJSON(r.Context(), w, usage)
+}
+
+func (c *adminController) setRole(w http.ResponseWriter, r *http.Request) error {
+	userUID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		return api.NewError(http.StatusBadRequest, api.WithError(err))
+	}
+
+	param := &struct {
+		Role string `json:"role"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(param); err != nil {
+		return api.NewError(http.StatusBadRequest, api.WithError(err))
+	}
+	defer r.Body.Close()
+
+	if !domain.ValidRole(param.Role) {
+		return api.NewError(http.StatusBadRequest, api.WithMessage("unknown role: "+param.Role))
+	}
+
+	session := r.Context().Value(api.SessionKey{}).(*api.Session)
+	if session.UserID == userUID && param.Role != domain.RoleAdmin {
+		return api.NewError(http.StatusBadRequest, api.WithMessage("cannot revoke your own admin role"))
+	}
+
+	if err := c.service.SetRole(r.Context(), userUID, param.Role); err != nil {
+		return api.NewError(http.StatusInternalServerError, api.WithError(err))
+	}
+
+	return api.ResponseStatusCode(r.Context(), w, http.StatusOK, "success to update role")
+}
+
+func (c *adminController) disableUser(w http.ResponseWriter, r *http.Request) error {
+	userUID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		return api.NewError(http.StatusBadRequest, api.WithError(err))
+	}
+
+	session := r.Context().Value(api.SessionKey{}).(*api.Session)
+	if session.UserID == userUID {
+		return api.NewError(http.StatusBadRequest, api.WithMessage("cannot disable your own account"))
+	}
+
+	if err := c.service.SetDisabled(r.Context(), userUID, true); err != nil {
+		return api.NewError(http.StatusInternalServerError, api.WithError(err))
+	}
+
+	// A disabled account must not keep the sessions it already has.
+	if _, err := c.sessions.DeleteByUser(r.Context(), userUID); err != nil {
+		return api.NewError(http.StatusInternalServerError, api.WithError(err))
+	}
+
+	return api.ResponseStatusCode(r.Context(), w, http.StatusOK, "success to disable user")
+}
+
+func (c *adminController) enableUser(w http.ResponseWriter, r *http.Request) error {
+	userUID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		return api.NewError(http.StatusBadRequest, api.WithError(err))
+	}
+
+	if err := c.service.SetDisabled(r.Context(), userUID, false); err != nil {
+		return api.NewError(http.StatusInternalServerError, api.WithError(err))
+	}
+
+	return api.ResponseStatusCode(r.Context(), w, http.StatusOK, "success to enable user")
+}
+
+func (c *adminController) deleteSessions(w http.ResponseWriter, r *http.Request) error {
+	userUID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		return api.NewError(http.StatusBadRequest, api.WithError(err))
+	}
+
+	deleted, err := c.sessions.DeleteByUser(r.Context(), userUID)
+	if err != nil {
+		return api.NewError(http.StatusInternalServerError, api.WithError(err))
+	}
+
+	return api.ResponseJSON(r.Context(), w, map[string]int{"deleted": deleted})
+}
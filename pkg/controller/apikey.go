@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/api"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/repository"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/service"
+)
+
+type apiKeyController struct {
+	service *service.APIKeyService
+}
+
+func NewAPIKeyController(service *service.APIKeyService) *apiKeyController {
+	return &apiKeyController{
+		service: service,
+	}
+}
+
+var _ api.APIGroup = (*apiKeyController)(nil)
+
+func (c *apiKeyController) ListAPIs() []*api.API {
+	return []*api.API{
+		api.NewSimpleAPI("POST /api/users/{userID}/api-keys", c.createAPIKey),
+		api.NewSimpleAPI("GET /api/users/{userID}/api-keys", c.listAPIKeys),
+		api.NewSimpleAPI("DELETE /api/users/{userID}/api-keys/{keyID}", c.deleteAPIKey),
+	}
+}
+
+// createAPIKey responds with the key itself only this once. It writes the
+// response without api.ResponseJSON, which would log the key.
+func (c *apiKeyController) createAPIKey(w http.ResponseWriter, r *http.Request) error {
+	userUID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		return api.NewError(http.StatusBadRequest, api.WithError(err))
+	}
+
+	param := &struct {
+		Name   string   `json:"name"`
+		Scopes []string `json:"scopes"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(param); err != nil {
+		return api.NewError(http.StatusBadRequest, api.WithError(err))
+	}
+	defer r.Body.Close()
+
+	if param.Name == "" {
+		return api.NewError(http.StatusBadRequest, api.WithMessage("name is required"))
+	}
+
+	key, apiKey, err := c.service.CreateAPIKey(r.Context(), userUID, param.Name, param.Scopes)
+	if errors.Is(err, service.ErrInvalidAPIKeyScope) {
+		return api.NewError(http.StatusBadRequest, api.WithError(err))
+	}
+	if err != nil {
+		return api.NewError(http.StatusInternalServerError, api.WithError(err))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(map[string]interface{}{
+		"key":     key,
+		"api_key": apiKey.Info(),
+	})
+}
+
+func (c *apiKeyController) listAPIKeys(w http.ResponseWriter, r *http.Request) error {
+	userUID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		return api.NewError(http.StatusBadRequest, api.WithError(err))
+	}
+
+	keys, err := c.service.ListAPIKeys(r.Context(), userUID)
+	if err != nil {
+		return api.NewError(http.StatusInternalServerError, api.WithError(err))
+	}
+
+	infos := make([]*domain.APIKeyInfo, 0, len(keys))
+	for _, k := range keys {
+		infos = append(infos, k.Info())
+	}
+
+	return api.ResponseJSON(r.Context(), w, infos)
+}
+
+func (c *apiKeyController) deleteAPIKey(w http.ResponseWriter, r *http.Request) error {
+	userUID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		return api.NewError(http.StatusBadRequest, api.WithError(err))
+	}
+	keyUID, err := uuid.Parse(r.PathValue("keyID"))
+	if err != nil {
+		return api.NewError(http.StatusBadRequest, api.WithError(err))
+	}
+
+	err = c.service.RevokeAPIKey(r.Context(), userUID, keyUID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return api.NewError(http.StatusNotFound, api.WithError(err))
+	}
+	if err != nil {
+		return api.NewError(http.StatusInternalServerError, api.WithError(err))
+	}
+
+	return api.ResponseStatusCode(r.Context(), w, http.StatusOK, "success to revoke api key")
+}
@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/api"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/service"
+)
+
+const maxArchiveSize = 32 << 20
+
+type archiveController struct {
+	service *service.ArchiveService
+}
+
+func NewArchiveController(service *service.ArchiveService) *archiveController {
+	return &archiveController{
+		service: service,
+	}
+}
+
+var _ api.APIGroup = (*archiveController)(nil)
+
+func (c *archiveController) ListAPIs() []*api.API {
+	return []*api.API{
+		api.NewSimpleAPI("GET /api/users/{userID}/export", c.exportUserData),
+		api.NewSimpleAPI("POST /api/users/{userID}/import", c.importUserData),
+	}
+}
+
+func (c *archiveController) exportUserData(w http.ResponseWriter, r *http.Request) error {
+	userID := r.PathValue("userID")
+
+	userUID, err := uuid.Parse(userID)
+	if err != nil {
+		return api.NewError(http.StatusBadRequest, api.WithError(err))
+	}
+
+	archive, err := c.service.Export(r.Context(), userUID)
+	if err != nil {
+		return api.NewError(http.StatusInternalServerError, api.WithError(err))
+	}
+	records := archive.Records()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set(
+		"Content-Disposition",
+		fmt.Sprintf(`attachment; filename="notion-mindmap-%s.jsonl"`, userUID.String()),
+	)
+	w.WriteHeader(http.StatusOK)
+
+	// The status is already sent, so a failure while streaming can only be logged.
+	requestID := r.Context().Value(api.RequestIDKey{}).(uuid.UUID)
+	enc := json.NewEncoder(w)
+	for _, record := range records {
+		if err := enc.Encode(record); err != nil {
+			log.Printf("requestID: %s, fail to write export: %v", requestID.String(), err)
+			return nil
+		}
+	}
+	log.Printf("requestID: %s, result: exported %d records", requestID.String(), len(records))
+
+	return nil
+}
+
+func (c *archiveController) importUserData(w http.ResponseWriter, r *http.Request) error {
+	userID := r.PathValue("userID")
+
+	userUID, err := uuid.Parse(userID)
+	if err != nil {
+		return api.NewError(http.StatusBadRequest, api.WithError(err))
+	}
+
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		mode = domain.ImportModeMerge
+	}
+	if mode != domain.ImportModeMerge && mode != domain.ImportModeReplace {
+		return api.NewError(http.StatusBadRequest, api.WithMessage("mode must be merge or replace"))
+	}
+
+	archive, err := service.ReadArchive(http.MaxBytesReader(w, r.Body, maxArchiveSize))
+	if err != nil {
+		return api.NewError(http.StatusBadRequest, api.WithError(err))
+	}
+
+	report, err := c.service.Import(r.Context(), userUID, archive, mode)
+	if errors.Is(err, service.ErrInvalidArchive) {
+		return api.NewError(http.StatusBadRequest, api.WithError(err))
+	}
+	if err != nil {
+		return api.NewError(http.StatusInternalServerError, api.WithError(err))
+	}
+
+	return api.ResponseJSON(r.Context(), w, report)
+}
@@ -1,13 +1,8 @@
 package controller
 
 import (
-	"bytes"
 	"context"
-	"encoding/base64"
-	"encoding/json"
 	"errors"
-	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 
@@ -16,35 +11,45 @@ import (
 	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/api"
 	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/config"
 	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/notion"
 	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/service"
 )
 
-const (
-	tokenURL = "https://api.notion.com/v1/oauth/token"
-)
-
 type authController struct {
-	service      *service.UserService
-	clientID     string
-	clientSecret string
-	authURL      string
-	state        string
+	service   *service.UserService
+	sessions  *api.SessionManager
+	tokens    *service.TokenManager
+	notion    *notionClient
+	attempts  *authAttempts
+	redirects *redirectPolicy
+	external  *api.ExternalURL
+	authURL   string
 }
 
 func NewAuthController(
 	service *service.UserService,
+	sessions *api.SessionManager,
+	tokens *service.TokenManager,
+	notion *notionClient,
+	external *api.ExternalURL,
+	attempts api.AuthAttemptStore,
 	cfg *config.OAuthConfig,
+	frontend *config.FrontendConfig,
 ) (*authController, error) {
-	if cfg.ClientID == "" || cfg.ClientSecret == "" {
-		return nil, errors.New("required oauth config")
+	redirects, err := newRedirectPolicy(frontend)
+	if err != nil {
+		return nil, err
 	}
 
 	return &authController{
-		service:      service,
-		clientID:     cfg.ClientID,
-		clientSecret: cfg.ClientSecret,
-		authURL:      cfg.AuthURL,
-		state:        cfg.State,
+		service:   service,
+		sessions:  sessions,
+		tokens:    tokens,
+		notion:    notion,
+		attempts:  newAuthAttempts(attempts),
+		redirects: redirects,
+		external:  external,
+		authURL:   cfg.AuthURL,
 	}, nil
 }
 
@@ -55,12 +60,22 @@ func (c *authController) ListAPIs() []*api.API {
 		api.NewSimpleAPI("GET /auth/notion", c.processNotionAuth),
 		api.NewSimpleAPI("GET /auth/notion/callback", c.processNotionAuthCallback),
 		api.NewSimpleAPI("GET /api/session/status", c.getSessionStatus),
+		api.NewSimpleAPI("GET /api/users/{userID}/notion/connection", c.getNotionConnection),
 	}
 }
 
 func (c *authController) processNotionAuth(w http.ResponseWriter, r *http.Request) error {
-	authURL := "https://api.notion.com/v1/oauth/authorize"
-	aURL, err := url.Parse(authURL)
+	returnTo, err := c.redirects.resolve(r.URL.Query().Get("return_to"))
+	if err != nil {
+		return api.NewError(http.StatusBadRequest, api.WithError(err))
+	}
+
+	attempt, err := c.attempts.issue(r.Context(), returnTo)
+	if err != nil {
+		return api.NewError(http.StatusInternalServerError, api.WithError(err))
+	}
+
+	aURL, err := url.Parse(c.authURL)
 	if err != nil {
 		return api.NewError(
 			http.StatusInternalServerError,
@@ -70,21 +85,33 @@ func (c *authController) processNotionAuth(w http.ResponseWriter, r *http.Reques
 	}
 
 	q := aURL.Query()
-	q.Set("client_id", c.clientID)
+	q.Set("client_id", c.notion.ClientID())
 	q.Set("response_type", "code")
 	q.Set("owner", "user")
-	q.Set("redirect_uri", fmt.Sprintf("http://%s/auth/notion/callback", r.Host))
-	q.Set("state", c.state)
+	q.Set("redirect_uri", c.external.Resolve(r, "/auth/notion/callback"))
+	q.Set("state", attempt.State)
+	q.Set("code_challenge", codeChallenge(attempt))
+	q.Set("code_challenge_method", "S256")
 	aURL.RawQuery = q.Encode()
 
+	http.SetCookie(w, c.authAttemptCookie(attempt.ID, int(authAttemptTTL.Seconds())))
 	http.Redirect(w, r, aURL.String(), http.StatusTemporaryRedirect)
 	return nil
 }
 
 func (c *authController) processNotionAuthCallback(w http.ResponseWriter, r *http.Request) error {
-	queryState := r.URL.Query().Get("state")
-	if queryState != c.state {
-		return api.NewError(http.StatusBadRequest, api.WithMessage("invalid state"))
+	cookie, err := r.Cookie(authAttemptCookieName)
+	if err != nil {
+		return api.NewError(http.StatusBadRequest, api.WithError(errUnknownAuthAttempt))
+	}
+	http.SetCookie(w, c.authAttemptCookie("", -1))
+
+	attempt, err := c.attempts.consume(r.Context(), cookie.Value, r.URL.Query().Get("state"))
+	if errors.Is(err, errUnknownAuthAttempt) || errors.Is(err, errStateMismatch) {
+		return api.NewError(http.StatusBadRequest, api.WithError(err))
+	}
+	if err != nil {
+		return api.NewError(http.StatusInternalServerError, api.WithError(err))
 	}
 
 	code := r.URL.Query().Get("code")
@@ -92,9 +119,11 @@ func (c *authController) processNotionAuthCallback(w http.ResponseWriter, r *htt
 		return api.NewError(http.StatusBadRequest, api.WithMessage("authorization code 누락"))
 	}
 
-	tok, err := c.requestToken(
+	tok, err := c.notion.requestToken(
+		r.Context(),
 		code,
-		fmt.Sprintf("http://%s/auth/notion/callback", r.Host),
+		c.external.Resolve(r, "/auth/notion/callback"),
+		attempt.Verifier,
 	)
 	if err != nil {
 		return api.NewError(
@@ -104,7 +133,7 @@ func (c *authController) processNotionAuthCallback(w http.ResponseWriter, r *htt
 		)
 	}
 
-	user, err := c.getNotionUser(tok.AccessToken)
+	user, err := c.notion.getNotionUser(r.Context(), tok.AccessToken)
 	if err != nil {
 		return api.NewError(
 			http.StatusInternalServerError,
@@ -120,12 +149,16 @@ func (c *authController) processNotionAuthCallback(w http.ResponseWriter, r *htt
 	}
 	ctx := context.WithValue(r.Context(), api.RequestIDKey{}, requestID)
 
-	id, err := c.service.CreateUser(ctx, &domain.User{
+	id, err := c.service.SignIn(ctx, &domain.User{
 		Nickname:     user.Name,
 		NotionUserID: notionUserID,
 		AccessToken:  tok.AccessToken,
 		RefreshToken: tok.RefreshToken,
+		NotionBotID:  tok.BotID,
 	})
+	if errors.Is(err, service.ErrUserDisabled) {
+		return api.NewError(http.StatusForbidden, api.WithError(err))
+	}
 	if err != nil {
 		return api.NewError(http.StatusInternalServerError, api.WithError(err))
 	}
@@ -137,107 +170,78 @@ func (c *authController) processNotionAuthCallback(w http.ResponseWriter, r *htt
 		Token:        tok,
 	}
 
-	api.SessionStore.Set(id.String(), session)
-	http.SetCookie(w, &http.Cookie{
-		Name:     "sessionID",
-		Value:    id.String(),
-		Path:     "/",
-		HttpOnly: true,
-	})
-
-	http.Redirect(w, r, "http://localhost:3000/?auth=success", http.StatusTemporaryRedirect)
-	return nil
-}
-
-func (c *authController) requestToken(code, redirectURI string) (*api.Token, error) {
-	payload := map[string]string{
-		"grant_type":   "authorization_code",
-		"code":         code,
-		"redirect_uri": redirectURI,
-	}
-	body, _ := json.Marshal(payload)
-	req, err := http.NewRequest("POST", tokenURL, bytes.NewReader(body))
-	if err != nil {
-		return nil, err
+	if err := c.sessions.Start(w, r.WithContext(ctx), session); err != nil {
+		return api.NewError(http.StatusInternalServerError, api.WithError(err))
 	}
-	basicAuth := base64.StdEncoding.EncodeToString([]byte(c.clientID + ":" + c.clientSecret))
-	req.Header.Set("Authorization", "Basic "+basicAuth)
-	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	redirectURL, err := url.Parse(attempt.ReturnTo)
 	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		data, _ := io.ReadAll(resp.Body)
-		return nil, errors.New(string(data))
-	}
-
-	tok := &api.Token{}
-	if err := json.NewDecoder(resp.Body).Decode(tok); err != nil {
-		return nil, err
+		return api.NewError(http.StatusInternalServerError, api.WithError(err))
 	}
+	q := redirectURL.Query()
+	q.Set("auth", "success")
+	redirectURL.RawQuery = q.Encode()
 
-	return tok, nil
-}
-
-type notionUser struct {
-	Object string `json:"object"`
-	ID     string `json:"id"`
-	Name   string `json:"name"`
-	Type   string `json:"type"`
-	Person struct {
-		Email string `json:"email"`
-	} `json:"person"`
-}
-
-type notionUserResp struct {
-	Object    string `json:"object"`
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	AvatarURL string `json:"avatar_url"`
-	Type      string `json:"type"`
-	Bot       struct {
-		Owner struct {
-			Type string      `json:"type"`
-			User *notionUser `json:"user"`
-		} `json:"owner"`
-	} `json:"bot"`
+	http.Redirect(w, r, redirectURL.String(), http.StatusTemporaryRedirect)
+	return nil
 }
 
-func (c *authController) getNotionUser(accessToken string) (*notionUser, error) {
-	req, err := http.NewRequest("GET", "https://api.notion.com/v1/users/me", nil)
-	if err != nil {
-		return nil, err
+// authAttemptCookie only goes to the callback. It has to be lax, since the
+// callback is reached through a redirect from notion.
+func (c *authController) authAttemptCookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     authAttemptCookieName,
+		Value:    value,
+		Path:     "/auth/notion/callback",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   c.sessions.SecureCookies(),
+		SameSite: http.SameSiteLaxMode,
 	}
+}
 
-	req.Header.Set("Authorization", "Bearer "+accessToken)
-	req.Header.Set("Notion-Version", "2022-06-28")
+func (c *authController) getSessionStatus(w http.ResponseWriter, r *http.Request) error {
+	session := r.Context().Value(api.SessionKey{}).(*api.Session)
 
-	resp, err := http.DefaultClient.Do(req)
+	connected, err := c.tokens.Connected(r.Context(), session.UserID)
 	if err != nil {
-		return nil, err
+		return api.NewError(http.StatusInternalServerError, api.WithError(err))
 	}
 
-	data := &notionUserResp{}
-	if err := json.NewDecoder(resp.Body).Decode(data); err != nil {
-		return nil, err
+	response := map[string]interface{}{
+		"authenticated":    true,
+		"user_id":          session.UserID,
+		"notion_user_id":   session.NotionUserID,
+		"notion_connected": connected,
 	}
-	defer resp.Body.Close()
 
-	return data.Bot.Owner.User, nil
+	return api.ResponseJSON(r.Context(), w, response)
 }
 
-func (c *authController) getSessionStatus(w http.ResponseWriter, r *http.Request) error {
-	session := r.Context().Value(api.SessionKey{}).(*api.Session)
+// getNotionConnection checks the notion tokens of the user against notion,
+// refreshing them if they expired.
+func (c *authController) getNotionConnection(w http.ResponseWriter, r *http.Request) error {
+	userUID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		return api.NewError(http.StatusBadRequest, api.WithError(err))
+	}
 
-	response := map[string]interface{}{
-		"authenticated":  true,
-		"user_id":        session.UserID,
-		"notion_user_id": session.NotionUserID,
+	var user *notion.User
+	err = c.tokens.Do(r.Context(), userUID, func(ctx context.Context, accessToken string) error {
+		user, err = c.notion.getNotionUser(ctx, accessToken)
+		return err
+	})
+	if errors.Is(err, service.ErrNotionDisconnected) {
+		return api.ResponseJSON(r.Context(), w, map[string]interface{}{
+			"connected": false,
+		})
+	}
+	if err != nil {
+		return api.NewError(http.StatusBadGateway, api.WithError(err))
 	}
 
-	return api.ResponseJSON(r.Context(), w, response)
+	return api.ResponseJSON(r.Context(), w, map[string]interface{}{
+		"connected":   true,
+		"notion_user": user,
+	})
 }
@@ -0,0 +1,182 @@
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/api"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/config"
+)
+
+const (
+	authAttemptCookieName = "notionAuth"
+	authAttemptTTL        = 10 * time.Minute
+)
+
+var (
+	errUnknownAuthAttempt = errors.New("the sign in attempt expired or was already used")
+	errStateMismatch      = errors.New("invalid state")
+	errInvalidReturnTo    = errors.New("return_to is not an allowed redirect target")
+)
+
+type authAttempt = api.AuthAttempt
+
+// codeChallenge is the S256 PKCE challenge of the verifier.
+func codeChallenge(attempt *authAttempt) string {
+	sum := sha256.Sum256([]byte(attempt.Verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// authAttempts issues and consumes the sign in attempts kept in store. With a
+// shared store the callback may reach another replica than the sign in did.
+type authAttempts struct {
+	store api.AuthAttemptStore
+	now   func() time.Time
+}
+
+func newAuthAttempts(store api.AuthAttemptStore) *authAttempts {
+	return &authAttempts{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+func (s *authAttempts) issue(ctx context.Context, returnTo string) (*authAttempt, error) {
+	id, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	state, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	attempt := &authAttempt{
+		ID:        id,
+		State:     state,
+		Verifier:  verifier,
+		ReturnTo:  returnTo,
+		ExpiresAt: now.Add(authAttemptTTL),
+	}
+
+	if _, err := s.store.DeleteExpired(ctx, now); err != nil {
+		return nil, err
+	}
+	if err := s.store.Put(ctx, attempt); err != nil {
+		return nil, err
+	}
+
+	return attempt, nil
+}
+
+// consume removes the attempt and returns it if it is still valid for state.
+// A mismatching state burns the attempt too, so it cannot be guessed at.
+func (s *authAttempts) consume(ctx context.Context, id, state string) (*authAttempt, error) {
+	attempt, ok, err := s.store.Take(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok || s.now().After(attempt.ExpiresAt) {
+		return nil, errUnknownAuthAttempt
+	}
+	if subtle.ConstantTimeCompare([]byte(attempt.State), []byte(state)) != 1 {
+		return nil, errStateMismatch
+	}
+
+	return attempt, nil
+}
+
+// redirectPolicy decides where a sign in may return to: the frontend and the
+// configured allowed origins.
+type redirectPolicy struct {
+	frontend *url.URL
+	origins  map[string]bool
+}
+
+func newRedirectPolicy(cfg *config.FrontendConfig) (*redirectPolicy, error) {
+	frontend, err := parseOriginURL(cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &redirectPolicy{
+		frontend: frontend,
+		origins: map[string]bool{
+			frontend.Scheme + "://" + frontend.Host: true,
+		},
+	}
+	for _, allowed := range cfg.AllowedRedirects {
+		u, err := parseOriginURL(allowed)
+		if err != nil {
+			return nil, err
+		}
+		p.origins[u.Scheme+"://"+u.Host] = true
+	}
+
+	return p, nil
+}
+
+func parseOriginURL(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("redirect target must be an absolute http(s) url: %s", s)
+	}
+
+	return u, nil
+}
+
+// resolve turns return_to into the absolute url to send the user to after
+// signing in. A path is taken relative to the frontend; an absolute url must
+// be on an allowed origin.
+func (p *redirectPolicy) resolve(returnTo string) (string, error) {
+	if returnTo == "" {
+		return p.frontend.String(), nil
+	}
+	if strings.ContainsAny(returnTo, "\\\r\n") {
+		return "", errInvalidReturnTo
+	}
+
+	u, err := url.Parse(returnTo)
+	if err != nil || u.User != nil {
+		return "", errInvalidReturnTo
+	}
+
+	if u.Scheme == "" && u.Host == "" {
+		if !strings.HasPrefix(u.Path, "/") {
+			return "", errInvalidReturnTo
+		}
+		return p.frontend.ResolveReference(u).String(), nil
+	}
+
+	if !p.origins[u.Scheme+"://"+u.Host] {
+		return "", errInvalidReturnTo
+	}
+
+	return u.String(), nil
+}
+
+func randomString() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
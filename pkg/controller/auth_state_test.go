@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/api"
+)
+
+func newTestAuthAttempts(store api.AuthAttemptStore, now *time.Time) *authAttempts {
+	attempts := newAuthAttempts(store)
+	attempts.now = func() time.Time { return *now }
+	return attempts
+}
+
+func TestAuthAttemptsConsume(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	attempts := newTestAuthAttempts(api.NewMemoryAuthAttemptStore(), &now)
+
+	issued, err := attempts.issue(ctx, "https://app.example.com/maps")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	consumed, err := attempts.consume(ctx, issued.ID, issued.State)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if consumed.Verifier != issued.Verifier || consumed.ReturnTo != issued.ReturnTo {
+		t.Fatalf("consumed attempt %+v differs from the issued %+v", consumed, issued)
+	}
+}
+
+func TestAuthAttemptsStateMismatch(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	attempts := newTestAuthAttempts(api.NewMemoryAuthAttemptStore(), &now)
+
+	issued, err := attempts.issue(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := attempts.consume(ctx, issued.ID, "forged"); !errors.Is(err, errStateMismatch) {
+		t.Fatalf("consume with a forged state = %v, want %v", err, errStateMismatch)
+	}
+	// The attempt is burnt, so the right state cannot be tried afterwards.
+	if _, err := attempts.consume(ctx, issued.ID, issued.State); !errors.Is(err, errUnknownAuthAttempt) {
+		t.Fatalf("consume after a mismatch = %v, want %v", err, errUnknownAuthAttempt)
+	}
+}
+
+func TestAuthAttemptsReplay(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	attempts := newTestAuthAttempts(api.NewMemoryAuthAttemptStore(), &now)
+
+	issued, err := attempts.issue(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := attempts.consume(ctx, issued.ID, issued.State); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := attempts.consume(ctx, issued.ID, issued.State); !errors.Is(err, errUnknownAuthAttempt) {
+		t.Fatalf("replayed callback = %v, want %v", err, errUnknownAuthAttempt)
+	}
+}
+
+func TestAuthAttemptsExpiry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := api.NewMemoryAuthAttemptStore()
+	attempts := newTestAuthAttempts(store, &now)
+
+	expired, err := attempts.issue(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(authAttemptTTL + time.Second)
+
+	if _, err := attempts.consume(ctx, expired.ID, expired.State); !errors.Is(err, errUnknownAuthAttempt) {
+		t.Fatalf("consume of an expired attempt = %v, want %v", err, errUnknownAuthAttempt)
+	}
+
+	// Issuing sweeps the expired attempts out of the store.
+	stale, err := attempts.issue(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(authAttemptTTL + time.Second)
+	if _, err := attempts.issue(ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := store.Take(ctx, stale.ID); ok {
+		t.Fatal("expired attempt was not swept")
+	}
+}
+
+func TestAuthAttemptsSharedStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := api.NewMemoryAuthAttemptStore()
+	first := newTestAuthAttempts(store, &now)
+	second := newTestAuthAttempts(store, &now)
+
+	issued, err := first.issue(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := second.consume(ctx, issued.ID, issued.State); err != nil {
+		t.Fatalf("callback on another replica = %v, want success", err)
+	}
+	if _, err := first.consume(ctx, issued.ID, issued.State); !errors.Is(err, errUnknownAuthAttempt) {
+		t.Fatalf("replay on the issuing replica = %v, want %v", err, errUnknownAuthAttempt)
+	}
+}
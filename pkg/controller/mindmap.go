@@ -26,6 +26,7 @@ var _ api.APIGroup = (*mindMapController)(nil)
 func (c *mindMapController) ListAPIs() []*api.API {
 	return []*api.API{
 		api.NewSimpleAPI("POST /api/users/{userID}/mindmap", c.createMindMap),
+		api.NewSimpleAPI("PUT /api/users/{userID}/mindmap", c.rebuildMindMap),
 		api.NewSimpleAPI("GET /api/users/{userID}/mindmap", c.getMindMap),
 		api.NewSimpleAPI("DELETE /api/users/{userID}/mindmap", c.deleteMindMap),
 	}
@@ -39,11 +40,6 @@ func (c *mindMapController) createMindMap(w http.ResponseWriter, r *http.Request
 		return api.NewError(http.StatusBadRequest, api.WithError(err))
 	}
 
-	// session, ok := r.Context().Value(api.SessionKey{}).(*api.Session)
-	// if !ok || session.UserID != userUID {
-	// 	return api.ErrInvalidSession
-	// }
-
 	params := &struct {
 		Nodes []*domain.KeywordNode `json:"nodes"`
 		Edges []*domain.EdgeOfIndex `json:"edges"`
@@ -64,7 +60,7 @@ func (c *mindMapController) createMindMap(w http.ResponseWriter, r *http.Request
 	return api.ResponseStatusCode(r.Context(), w, http.StatusCreated, "success to create mindmap")
 }
 
-func (c *mindMapController) getMindMap(w http.ResponseWriter, r *http.Request) error {
+func (c *mindMapController) rebuildMindMap(w http.ResponseWriter, r *http.Request) error {
 	userID := r.PathValue("userID")
 
 	userUID, err := uuid.Parse(userID)
@@ -72,10 +68,33 @@ func (c *mindMapController) getMindMap(w http.ResponseWriter, r *http.Request) e
 		return api.NewError(http.StatusBadRequest, api.WithError(err))
 	}
 
-	// session := r.Context().Value(api.SessionKey{}).(*api.Session)
-	// if session.UserID != userUID {
-	// 	return api.ErrInvalidSession
-	// }
+	params := &struct {
+		Nodes []*domain.KeywordNode `json:"nodes"`
+		Edges []*domain.EdgeOfIndex `json:"edges"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(params); err != nil {
+		return api.NewError(http.StatusBadRequest, api.WithError(err))
+	}
+
+	for _, e := range params.Edges {
+		if e.Idx1 < 0 || e.Idx2 < 0 || e.Idx1 >= len(params.Nodes) || e.Idx2 >= len(params.Nodes) {
+			return api.NewError(http.StatusBadRequest, api.WithMessage("invalid index"))
+		}
+	}
+
+	if err := c.service.RebuildMindMap(r.Context(), userUID, params.Nodes, params.Edges); err != nil {
+		return api.NewError(http.StatusInternalServerError, api.WithError(err))
+	}
+	return api.ResponseStatusCode(r.Context(), w, http.StatusOK, "success to rebuild mindmap")
+}
+
+func (c *mindMapController) getMindMap(w http.ResponseWriter, r *http.Request) error {
+	userID := r.PathValue("userID")
+
+	userUID, err := uuid.Parse(userID)
+	if err != nil {
+		return api.NewError(http.StatusBadRequest, api.WithError(err))
+	}
 
 	graph := c.service.GetMindMapByUser(r.Context(), userUID)
 
@@ -90,11 +109,6 @@ func (c *mindMapController) deleteMindMap(w http.ResponseWriter, r *http.Request
 		return api.NewError(http.StatusBadRequest, api.WithError(err))
 	}
 
-	// session := r.Context().Value(api.SessionKey{}).(*api.Session)
-	// if session.UserID != userUID {
-	// 	return api.ErrInvalidSession
-	// }
-
 	if err := c.service.DeleteMindMapByUser(r.Context(), userUID); err != nil {
 		return api.NewError(http.StatusInternalServerError, api.WithError(err))
 	}
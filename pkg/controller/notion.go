@@ -2,6 +2,7 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"log"
 	"net/http"
@@ -15,11 +16,16 @@ import (
 
 type notionPageController struct {
 	service *service.NotionPageService
+	sync    *service.NotionSyncService
 }
 
-func NewNotionPageController(service *service.NotionPageService) *notionPageController {
+func NewNotionPageController(
+	service *service.NotionPageService,
+	sync *service.NotionSyncService,
+) *notionPageController {
 	return &notionPageController{
 		service: service,
+		sync:    sync,
 	}
 }
 
@@ -29,6 +35,7 @@ func (c *notionPageController) ListAPIs() []*api.API {
 	return []*api.API{
 		api.NewSimpleAPI("POST /api/users/{userID}/notion", c.createNotionPage),
 		api.NewSimpleAPI("POST /api/users/{userID}/notion/bulk", c.createNotionPagesBulk),
+		api.NewSimpleAPI("POST /api/users/{userID}/notion/sync", c.syncNotionPages),
 		api.NewSimpleAPI("GET /api/users/{userID}/notion", c.getAllNotionPages),
 		api.NewSimpleAPI("GET /api/users/{userID}/notion/{notionPageID}", c.getNotionPage),
 		api.NewSimpleAPI("PUT /api/users/{userID}/notion/{notionPageID}", c.updateNotionPage),
@@ -46,18 +53,12 @@ func (c *notionPageController) createNotionPage(w http.ResponseWriter, r *http.R
 		return api.NewError(http.StatusBadRequest, api.WithError(err))
 	}
 
-	// session := r.Context().Value(api.SessionKey{}).(*api.Session)
-	// if session.UserID != userUID {
-	// 	return api.ErrInvalidSession
-	// }
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		return api.NewError(http.StatusInternalServerError, api.WithError(err))
 	}
 	defer r.Body.Close()
 
-	log.Println("requestID:", requestID.String(), "body:", string(body))
-
 	param := &domain.NotionPage{}
 	if err := json.Unmarshal(body, &param); err != nil {
 		return api.NewError(http.StatusBadRequest, api.WithError(err))
@@ -76,25 +77,17 @@ func (c *notionPageController) createNotionPage(w http.ResponseWriter, r *http.R
 func (c *notionPageController) createNotionPagesBulk(w http.ResponseWriter, r *http.Request) error {
 	userID := r.PathValue("userID")
 	requestID := r.Context().Value(api.RequestIDKey{}).(uuid.UUID)
-	log.Println("requestID:", requestID.String(), "userID:", userID, "bulk create")
-
 	userUID, err := uuid.Parse(userID)
 	if err != nil {
 		return api.NewError(http.StatusBadRequest, api.WithError(err))
 	}
 
-	// session := r.Context().Value(api.SessionKey{}).(*api.Session)
-	// if session.UserID != userUID {
-	// 	return api.ErrInvalidSession
-	// }
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		return api.NewError(http.StatusInternalServerError, api.WithError(err))
 	}
 	defer r.Body.Close()
 
-	log.Println("requestID:", requestID.String(), "body:", string(body))
-
 	var params []*struct {
 		Content      string `json:"content"`
 		NotionURL    string `json:"notion_url"`
@@ -105,6 +98,8 @@ func (c *notionPageController) createNotionPagesBulk(w http.ResponseWriter, r *h
 		return api.NewError(http.StatusBadRequest, api.WithError(err))
 	}
 
+	log.Println("requestID:", requestID.String(), "userID:", userID, "bulk create pages:", len(params))
+
 	pageParams := make([]*domain.NotionPage, len(params))
 	for i, param := range params {
 		if param.NotionPageID == "" {
@@ -138,6 +133,25 @@ func (c *notionPageController) createNotionPagesBulk(w http.ResponseWriter, r *h
 	return api.ResponseJSON(r.Context(), w, result)
 }
 
+// syncNotionPages imports the pages shared with the integration from notion
+// with the stored token of the user.
+func (c *notionPageController) syncNotionPages(w http.ResponseWriter, r *http.Request) error {
+	userUID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		return api.NewError(http.StatusBadRequest, api.WithError(err))
+	}
+
+	report, err := c.sync.Sync(r.Context(), userUID)
+	if errors.Is(err, service.ErrNotionDisconnected) {
+		return api.NewError(http.StatusConflict, api.WithError(err))
+	}
+	if err != nil {
+		return api.NewError(http.StatusBadGateway, api.WithError(err))
+	}
+
+	return api.ResponseJSON(r.Context(), w, report)
+}
+
 func (c *notionPageController) getAllNotionPages(w http.ResponseWriter, r *http.Request) error {
 	userID := r.PathValue("userID")
 
@@ -146,11 +160,6 @@ func (c *notionPageController) getAllNotionPages(w http.ResponseWriter, r *http.
 		return api.NewError(http.StatusBadRequest, api.WithError(err))
 	}
 
-	// session := r.Context().Value(api.SessionKey{}).(*api.Session)
-	// if session.UserID != userUID {
-	// 	return api.ErrInvalidSession
-	// }
-
 	pages, err := c.service.GetAllNotionPagesByUser(r.Context(), userUID)
 	if err != nil {
 		return api.NewError(http.StatusInternalServerError, api.WithError(err))
@@ -173,21 +182,31 @@ func (c *notionPageController) getNotionPage(w http.ResponseWriter, r *http.Requ
 		return api.NewError(http.StatusBadRequest, api.WithError(err))
 	}
 
-	// session := r.Context().Value(api.SessionKey{}).(*api.Session)
-	// if session.UserID != userUID {
-	// 	return api.ErrInvalidSession
-	// }
-
 	page, err := c.service.GetNotionPageByID(r.Context(), notionPageUID)
 	if err != nil {
 		return api.NewError(http.StatusInternalServerError, api.WithError(err))
 	}
 
 	if page.UserID != userUID {
-		return api.NewError(http.StatusForbidden, api.WithMessage("access denied"))
+		return api.ErrForbidden
 	}
 
-	return api.ResponseJSON(r.Context(), w, page)
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+		return api.ResponseJSON(r.Context(), w, page)
+	case "markdown":
+		// Pages uploaded by the client have no markdown, only their text.
+		markdown := page.Markdown
+		if markdown == "" {
+			markdown = page.Content
+		}
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, err := io.WriteString(w, markdown)
+		return err
+	default:
+		return api.NewError(http.StatusBadRequest, api.WithMessage("format must be json or markdown"))
+	}
 }
 
 func (c *notionPageController) updateNotionPage(w http.ResponseWriter, r *http.Request) error {
@@ -204,10 +223,9 @@ func (c *notionPageController) updateNotionPage(w http.ResponseWriter, r *http.R
 		return api.NewError(http.StatusBadRequest, api.WithError(err))
 	}
 
-	// session := r.Context().Value(api.SessionKey{}).(*api.Session)
-	// if session.UserID != userUID {
-	// 	return api.ErrInvalidSession
-	// }
+	if err := c.checkPageOwner(r, notionPageUID, userUID); err != nil {
+		return err
+	}
 
 	param := &domain.NotionPage{}
 	if err := json.NewDecoder(r.Body).Decode(param); err != nil {
@@ -240,19 +258,27 @@ func (c *notionPageController) deleteNotionPage(w http.ResponseWriter, r *http.R
 		return api.NewError(http.StatusBadRequest, api.WithError(err))
 	}
 
-	// session := r.Context().Value(api.SessionKey{}).(*api.Session)
-	// if session.UserID != userUID {
-	// 	return api.ErrInvalidSession
-	// }
+	if err := c.checkPageOwner(r, notionPageUID, userUID); err != nil {
+		return err
+	}
 
-	page, err := c.service.DeleteNotionPageByID(r.Context(), notionPageUID)
-	if err != nil {
+	if _, err := c.service.DeleteNotionPageByID(r.Context(), notionPageUID); err != nil {
 		return api.NewError(http.StatusInternalServerError, api.WithError(err))
 	}
 
-	if page.UserID != userUID {
-		return api.NewError(http.StatusForbidden, api.WithMessage("access denied"))
+	return api.ResponseStatusCode(r.Context(), w, http.StatusOK, "success to delete notion page")
+}
+
+// checkPageOwner keeps a user from reaching the pages of another user through
+// their own {userID} route.
+func (c *notionPageController) checkPageOwner(r *http.Request, pageID, userID uuid.UUID) error {
+	page, err := c.service.GetNotionPageByID(r.Context(), pageID)
+	if err != nil {
+		return api.NewError(http.StatusNotFound, api.WithError(err))
+	}
+	if page.UserID != userID {
+		return api.ErrForbidden
 	}
 
-	return api.ResponseStatusCode(r.Context(), w, http.StatusOK, "success to delete notion page")
+	return nil
 }
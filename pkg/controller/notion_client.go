@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/api"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/notion"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/service"
+)
+
+// notionClient adapts the notion client to the services, turning rejected
+// tokens into the errors of the service package.
+type notionClient struct {
+	*notion.Client
+}
+
+func NewNotionClient(client *notion.Client) *notionClient {
+	return &notionClient{
+		Client: client,
+	}
+}
+
+var (
+	_ service.TokenRefresher = (*notionClient)(nil)
+	_ service.NotionReader   = (*notionClient)(nil)
+)
+
+func (c *notionClient) requestToken(
+	ctx context.Context,
+	code, redirectURI, codeVerifier string,
+) (*api.Token, error) {
+	tok, err := c.ExchangeCode(ctx, code, redirectURI, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	return toToken(tok), nil
+}
+
+// RefreshToken exchanges the refresh token for a new token pair. It fails
+// with service.ErrRefreshRejected when notion refuses the refresh token.
+func (c *notionClient) RefreshToken(ctx context.Context, refreshToken string) (*api.Token, error) {
+	tok, err := c.Client.RefreshToken(ctx, refreshToken)
+	var notionErr *notion.Error
+	if errors.As(err, &notionErr) &&
+		(notionErr.StatusCode == http.StatusBadRequest || notionErr.StatusCode == http.StatusUnauthorized) {
+		return nil, fmt.Errorf("%w: %w", service.ErrRefreshRejected, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return toToken(tok), nil
+}
+
+func toToken(tok *notion.Token) *api.Token {
+	return &api.Token{
+		AccessToken:   tok.AccessToken,
+		RefreshToken:  tok.RefreshToken,
+		BotID:         tok.BotID,
+		WorkspaceName: tok.WorkspaceName,
+	}
+}
+
+// unauthorized turns a rejected access token into
+// service.ErrNotionUnauthorized, so the token manager refreshes it.
+func unauthorized(err error) error {
+	if notion.IsUnauthorized(err) {
+		return fmt.Errorf("%w: %w", service.ErrNotionUnauthorized, err)
+	}
+	return err
+}
+
+// getNotionUser returns the notion user who authorized the access token. It
+// fails with service.ErrNotionUnauthorized when notion rejects the token.
+func (c *notionClient) getNotionUser(ctx context.Context, accessToken string) (*notion.User, error) {
+	me, err := c.Me(ctx, accessToken)
+	if err != nil {
+		return nil, unauthorized(err)
+	}
+	if me.Bot == nil || me.Bot.Owner.User == nil {
+		return nil, errors.New("the token is not owned by a notion user")
+	}
+
+	return me.Bot.Owner.User, nil
+}
+
+func (c *notionClient) Search(
+	ctx context.Context,
+	accessToken string,
+	params *notion.SearchParams,
+) (*notion.List[*notion.Page], error) {
+	list, err := c.Client.Search(ctx, accessToken, params)
+	return list, unauthorized(err)
+}
+
+func (c *notionClient) ListBlockChildren(
+	ctx context.Context,
+	accessToken, blockID string,
+) ([]*notion.Block, error) {
+	blocks, err := c.Client.ListBlockChildren(ctx, accessToken, blockID)
+	return blocks, unauthorized(err)
+}
+
+func (c *notionClient) GetPage(ctx context.Context, accessToken, pageID string) (*notion.Page, error) {
+	page, err := c.Client.GetPage(ctx, accessToken, pageID)
+	return page, unauthorized(err)
+}
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/api"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+)
+
+type sessionController struct {
+	sessions *api.SessionManager
+}
+
+func NewSessionController(sessions *api.SessionManager) *sessionController {
+	return &sessionController{
+		sessions: sessions,
+	}
+}
+
+var _ api.APIGroup = (*sessionController)(nil)
+
+func (c *sessionController) ListAPIs() []*api.API {
+	return []*api.API{
+		api.NewSimpleAPI("POST /api/session/logout", c.logout),
+		api.NewSimpleAPI("GET /api/sessions", c.listSessions),
+		api.NewSimpleAPI("DELETE /api/sessions", c.deleteAllSessions),
+		api.NewSimpleAPI("DELETE /api/sessions/{id}", c.deleteSession),
+	}
+}
+
+func (c *sessionController) logout(w http.ResponseWriter, r *http.Request) error {
+	session := r.Context().Value(api.SessionKey{}).(*api.Session)
+
+	if err := c.sessions.Delete(r.Context(), session.ID); err != nil {
+		return api.NewError(http.StatusInternalServerError, api.WithError(err))
+	}
+	c.sessions.ClearCookie(w)
+
+	return api.ResponseStatusCode(r.Context(), w, http.StatusOK, "success to logout")
+}
+
+func (c *sessionController) listSessions(w http.ResponseWriter, r *http.Request) error {
+	session := r.Context().Value(api.SessionKey{}).(*api.Session)
+
+	sessions, err := c.sessions.ListByUser(r.Context(), session.UserID)
+	if err != nil {
+		return api.NewError(http.StatusInternalServerError, api.WithError(err))
+	}
+
+	infos := make([]*domain.SessionInfo, 0, len(sessions))
+	for _, s := range sessions {
+		infos = append(infos, s.Info(session.ID))
+	}
+
+	return api.ResponseJSON(r.Context(), w, infos)
+}
+
+// deleteAllSessions signs the user out everywhere, including this session.
+func (c *sessionController) deleteAllSessions(w http.ResponseWriter, r *http.Request) error {
+	session := r.Context().Value(api.SessionKey{}).(*api.Session)
+
+	deleted, err := c.sessions.DeleteByUser(r.Context(), session.UserID)
+	if err != nil {
+		return api.NewError(http.StatusInternalServerError, api.WithError(err))
+	}
+	c.sessions.ClearCookie(w)
+
+	return api.ResponseJSON(r.Context(), w, map[string]int{"deleted": deleted})
+}
+
+func (c *sessionController) deleteSession(w http.ResponseWriter, r *http.Request) error {
+	session := r.Context().Value(api.SessionKey{}).(*api.Session)
+	handle := r.PathValue("id")
+
+	sessions, err := c.sessions.ListByUser(r.Context(), session.UserID)
+	if err != nil {
+		return api.NewError(http.StatusInternalServerError, api.WithError(err))
+	}
+
+	for _, s := range sessions {
+		if s.Handle() != handle {
+			continue
+		}
+		if err := c.sessions.Delete(r.Context(), s.ID); err != nil {
+			return api.NewError(http.StatusInternalServerError, api.WithError(err))
+		}
+		if s.ID == session.ID {
+			c.sessions.ClearCookie(w)
+		}
+		return api.ResponseStatusCode(r.Context(), w, http.StatusOK, "success to delete session")
+	}
+
+	return api.NewError(http.StatusNotFound, api.WithMessage("not found session: "+handle))
+}
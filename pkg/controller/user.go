@@ -2,6 +2,7 @@ package controller
 
 import (
 	"encoding/json"
+	"log"
 	"net/http"
 	"time"
 
@@ -13,12 +14,14 @@ import (
 )
 
 type userController struct {
-	service *service.UserService
+	service  *service.UserService
+	sessions *api.SessionManager
 }
 
-func NewUserController(service *service.UserService) *userController {
+func NewUserController(service *service.UserService, sessions *api.SessionManager) *userController {
 	return &userController{
-		service: service,
+		service:  service,
+		sessions: sessions,
 	}
 }
 
@@ -36,6 +39,9 @@ func (c *userController) ListAPIs() []*api.API {
 
 func (c *userController) createUser(w http.ResponseWriter, r *http.Request) error {
 	session := r.Context().Value(api.SessionKey{}).(*api.Session)
+	if session.Token == nil {
+		return api.NewError(http.StatusBadRequest, api.WithMessage("sign in with notion to create a user"))
+	}
 
 	param := &domain.User{}
 	if err := json.NewDecoder(r.Body).Decode(param); err != nil {
@@ -52,7 +58,9 @@ func (c *userController) createUser(w http.ResponseWriter, r *http.Request) erro
 	}
 
 	session.UserID = id
-	api.SessionStore.Set(id.String(), session)
+	if err := c.sessions.Save(r.Context(), session); err != nil {
+		return api.NewError(http.StatusInternalServerError, api.WithError(err))
+	}
 	http.SetCookie(w, &http.Cookie{
 		Name:     "userID",
 		Value:    id.String(),
@@ -72,17 +80,12 @@ func (c *userController) getUser(w http.ResponseWriter, r *http.Request) error {
 		return api.NewError(http.StatusBadRequest, api.WithError(err))
 	}
 
-	// session := r.Context().Value(api.SessionKey{}).(*api.Session)
-	// if session.UserID != userUID {
-	// 	return api.ErrInvalidSession
-	// }
-
 	user, err := c.service.GetUser(r.Context(), userUID)
 	if err != nil {
 		return api.NewError(http.StatusInternalServerError, api.WithError(err))
 	}
 
-	return api.ResponseJSON(r.Context(), w, user)
+	return api.ResponseJSON(r.Context(), w, user.Account())
 }
 
 func (c *userController) updateUser(w http.ResponseWriter, r *http.Request) error {
@@ -93,11 +96,6 @@ func (c *userController) updateUser(w http.ResponseWriter, r *http.Request) erro
 		return api.NewError(http.StatusBadRequest, api.WithError(err))
 	}
 
-	// session := r.Context().Value(api.SessionKey{}).(*api.Session)
-	// if session.UserID != userUID {
-	// 	return api.ErrInvalidSession
-	// }
-
 	param := &struct {
 		Nickname string `json:"nickname"`
 	}{}
@@ -121,11 +119,6 @@ func (c *userController) updateUserTokens(w http.ResponseWriter, r *http.Request
 		return api.NewError(http.StatusBadRequest, api.WithError(err))
 	}
 
-	// session := r.Context().Value(api.SessionKey{}).(*api.Session)
-	// if session.UserID != userUID {
-	// 	return api.ErrInvalidSession
-	// }
-
 	param := &struct {
 		AccessToken  string `json:"access_token"`
 		RefreshToken string `json:"refresh_token"`
@@ -150,14 +143,31 @@ func (c *userController) deleteUser(w http.ResponseWriter, r *http.Request) erro
 		return api.NewError(http.StatusBadRequest, api.WithError(err))
 	}
 
-	// session := r.Context().Value(api.SessionKey{}).(*api.Session)
-	// if session.UserID != userUID {
-	// 	return api.ErrInvalidSession
-	// }
+	// The sessions are revoked first: a failure there leaves the account
+	// intact, while the account deletion below cannot be undone.
+	revoked, err := c.sessions.DeleteByUser(r.Context(), userUID)
+	if err != nil {
+		return api.NewError(http.StatusInternalServerError, api.WithError(err))
+	}
 
-	if err := c.service.DeleteUser(r.Context(), userUID); err != nil {
+	report, err := c.service.DeleteUser(r.Context(), userUID)
+	if err != nil {
 		return api.NewError(http.StatusInternalServerError, api.WithError(err))
 	}
+	report.Sessions = revoked
+
+	// A sign in racing the deletion may have started another session. The
+	// account is gone already, so a failure is reported instead of returned.
+	late, err := c.sessions.DeleteByUser(r.Context(), userUID)
+	if err != nil {
+		log.Printf("user %s is deleted but fail to revoke its sessions: %v", userUID.String(), err)
+		report.Failures = append(report.Failures, "revoke sessions: "+err.Error())
+	}
+	report.Sessions += late
+
+	if session, ok := r.Context().Value(api.SessionKey{}).(*api.Session); ok && session.UserID == userUID {
+		c.sessions.ClearCookie(w)
+	}
 
-	return api.ResponseStatusCode(r.Context(), w, http.StatusOK, "success to delete user")
+	return api.ResponseJSON(r.Context(), w, report)
 }
@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/api"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/config"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/notion"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/service"
+)
+
+const (
+	// maxWebhookBody bounds the size of a webhook delivery.
+	maxWebhookBody = 1 << 20
+	// verificationInterval is how often the arrival of a verification token
+	// is logged. Notion sends one per subscription, so anything more is noise.
+	verificationInterval = 10 * time.Second
+	// maxVerificationTokens bounds the unverified tokens kept for an admin.
+	// They are unauthenticated, so the newest ones are kept rather than
+	// refusing new ones, which could be the real delivery.
+	maxVerificationTokens = 16
+	verificationTokenTTL  = 24 * time.Hour
+
+	verificationTokensPath = "/api/admin/notion/webhook/verification"
+)
+
+// verificationToken is a token posted to the webhook while no secret was
+// configured. It is kept in memory by the instance which received it.
+type verificationToken struct {
+	Token      string    `json:"token"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+type webhookController struct {
+	service *service.NotionWebhookService
+	secret  string
+
+	mu         sync.Mutex
+	pending    []*verificationToken
+	lastLogged time.Time
+	now        func() time.Time
+}
+
+func NewWebhookController(service *service.NotionWebhookService, cfg *config.NotionConfig) *webhookController {
+	return &webhookController{
+		service: service,
+		secret:  cfg.WebhookSecret,
+		now:     time.Now,
+	}
+}
+
+var _ api.APIGroup = (*webhookController)(nil)
+
+// ListAPIs returns the webhook routes. The webhook is outside /api, so notion
+// reaches it without a session; the signature authenticates a delivery
+// instead.
+func (c *webhookController) ListAPIs() []*api.API {
+	return []*api.API{
+		api.NewSimpleAPI("POST /webhooks/notion", c.receiveNotionEvent),
+		api.NewSimpleAPI("GET "+verificationTokensPath, c.getVerificationTokens),
+	}
+}
+
+func (c *webhookController) receiveNotionEvent(w http.ResponseWriter, r *http.Request) error {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+	if err != nil {
+		return api.NewError(http.StatusRequestEntityTooLarge, api.WithError(err))
+	}
+	defer r.Body.Close()
+
+	event := &notion.WebhookEvent{}
+	if err := json.Unmarshal(body, event); err != nil {
+		return api.NewError(http.StatusBadRequest, api.WithError(err))
+	}
+
+	if c.secret == "" {
+		if event.VerificationToken == "" {
+			return api.NewError(http.StatusServiceUnavailable, api.WithMessage("notion webhooks are not configured"))
+		}
+		return c.receiveVerificationToken(w, r, event.VerificationToken)
+	}
+
+	signature := r.Header.Get(notion.SignatureHeader)
+	if !notion.VerifySignature(c.secret, body, signature) {
+		return api.NewError(http.StatusUnauthorized, api.WithMessage("invalid webhook signature"))
+	}
+
+	if _, err := c.service.Handle(r.Context(), event); err != nil {
+		return api.NewError(http.StatusInternalServerError, api.WithError(err))
+	}
+
+	return api.ResponseStatusCode(r.Context(), w, http.StatusOK, "accepted notion event")
+}
+
+// receiveVerificationToken keeps the token notion sends once when the
+// subscription is created, for an admin to read through
+// getVerificationTokens and paste back into notion. Anyone can post one while
+// no secret is configured, so every token is kept, the newest ones first, and
+// only the log line about them is rate limited. The token is never logged.
+func (c *webhookController) receiveVerificationToken(w http.ResponseWriter, r *http.Request, token string) error {
+	if c.keepVerificationToken(token) {
+		log.Printf("received a notion webhook verification token, " +
+			"an admin can read it from GET " + verificationTokensPath)
+	}
+
+	return api.ResponseStatusCode(r.Context(), w, http.StatusOK, "received verification token")
+}
+
+// keepVerificationToken stores token in front of the pending ones and reports
+// whether the arrival should be logged, which is at most once a window.
+func (c *webhookController) keepVerificationToken(token string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	pending := []*verificationToken{{Token: token, ReceivedAt: now}}
+	for _, p := range c.pending {
+		if p.Token == token || now.Sub(p.ReceivedAt) > verificationTokenTTL {
+			continue
+		}
+		if len(pending) == maxVerificationTokens {
+			break
+		}
+		pending = append(pending, p)
+	}
+	c.pending = pending
+
+	if !c.lastLogged.IsZero() && now.Sub(c.lastLogged) < verificationInterval {
+		return false
+	}
+	c.lastLogged = now
+
+	return true
+}
+
+// getVerificationTokens lists the verification tokens received since the
+// start, newest first. It is an admin route, see api.SetAdminChecker.
+func (c *webhookController) getVerificationTokens(w http.ResponseWriter, r *http.Request) error {
+	c.mu.Lock()
+	now := c.now()
+	tokens := make([]*verificationToken, 0, len(c.pending))
+	for _, p := range c.pending {
+		if now.Sub(p.ReceivedAt) <= verificationTokenTTL {
+			tokens = append(tokens, p)
+		}
+	}
+	c.mu.Unlock()
+
+	return api.ResponseJSON(r.Context(), w, map[string]interface{}{
+		"configured": c.secret != "",
+		"tokens":     tokens,
+	})
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/api"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/config"
+)
+
+// postWebhook sends body to the webhook and returns the status it answers with.
+func postWebhook(t *testing.T, c *webhookController, body string) int {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodPost, "/webhooks/notion", strings.NewReader(body))
+	r = r.WithContext(context.WithValue(r.Context(), api.RequestIDKey{}, uuid.New()))
+	w := httptest.NewRecorder()
+
+	err := c.receiveNotionEvent(w, r)
+	if err == nil {
+		return w.Code
+	}
+	var apiErr api.Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("webhook failed with %v", err)
+	}
+	return apiErr.StatusCode()
+}
+
+func TestWebhookVerificationTokensAreKeptForAdmins(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewWebhookController(nil, &config.NotionConfig{})
+	c.now = func() time.Time { return now }
+
+	if !strings.HasPrefix(verificationTokensPath, "/api/admin/") {
+		t.Fatalf("%s is not an admin route", verificationTokensPath)
+	}
+
+	// A flood right before the real delivery does not keep it out.
+	for i := 0; i < maxVerificationTokens; i++ {
+		body := fmt.Sprintf(`{"verification_token":"secret_forged_%d"}`, i)
+		if code := postWebhook(t, c, body); code != http.StatusOK {
+			t.Fatalf("forged verification token = %d, want %d", code, http.StatusOK)
+		}
+	}
+	if code := postWebhook(t, c, `{"verification_token":"secret_real"}`); code != http.StatusOK {
+		t.Fatalf("real verification token = %d, want %d", code, http.StatusOK)
+	}
+	postWebhook(t, c, `{"verification_token":"secret_real"}`)
+
+	r := httptest.NewRequest(http.MethodGet, verificationTokensPath, nil)
+	r = r.WithContext(context.WithValue(r.Context(), api.RequestIDKey{}, uuid.New()))
+	w := httptest.NewRecorder()
+	if err := c.getVerificationTokens(w, r); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(w.Body.String(), "secret_real") {
+		t.Fatalf("verification tokens %s miss the real one", w.Body.String())
+	}
+	if len(c.pending) != maxVerificationTokens || c.pending[0].Token != "secret_real" {
+		t.Fatalf("kept %d tokens starting with %q, want %d starting with the real one",
+			len(c.pending), c.pending[0].Token, maxVerificationTokens)
+	}
+
+	// Only the first arrival of the window is logged.
+	if c.keepVerificationToken("secret_other") {
+		t.Fatal("verification token logged twice in one window")
+	}
+	now = now.Add(verificationInterval)
+	if !c.keepVerificationToken("secret_other") {
+		t.Fatal("verification token not logged in the next window")
+	}
+}
+
+func TestWebhookVerificationTokenNeedsSignatureOnceConfigured(t *testing.T) {
+	c := NewWebhookController(nil, &config.NotionConfig{WebhookSecret: "secret_token"})
+
+	code := postWebhook(t, c, `{"verification_token":"secret_other"}`)
+	if code != http.StatusUnauthorized {
+		t.Fatalf("unsigned verification token with a secret configured = %d, want %d",
+			code, http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// APIKeyPrefix starts every api key, so leaked keys are easy to spot.
+	APIKeyPrefix = "nmk_"
+
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+)
+
+// APIKey lets scripts call the api on behalf of a user. Only the sha256 of the
+// key is stored; the key itself is shown once when it is created.
+type APIKey struct {
+	ID         uuid.UUID `json:"id"`
+	UserID     uuid.UUID `json:"user_id"`
+	Name       string    `json:"name"`
+	Hint       string    `json:"hint"`
+	Hash       string    `json:"hash"`
+	Scopes     []string  `json:"scopes"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+func (k *APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+func ValidScope(scope string) bool {
+	return scope == ScopeRead || scope == ScopeWrite
+}
+
+// APIKeyInfo is what a user sees about one of their api keys.
+type APIKeyInfo struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Hint       string     `json:"hint"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+func (k *APIKey) Info() *APIKeyInfo {
+	info := &APIKeyInfo{
+		ID:        k.ID,
+		Name:      k.Name,
+		Hint:      k.Hint,
+		Scopes:    k.Scopes,
+		CreatedAt: k.CreatedAt,
+	}
+	if !k.LastUsedAt.IsZero() {
+		lastUsedAt := k.LastUsedAt
+		info.LastUsedAt = &lastUsedAt
+	}
+
+	return info
+}
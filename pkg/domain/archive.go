@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ArchiveFormat        = "notion-mindmap-archive"
+	ArchiveFormatVersion = 1
+)
+
+// Archive record types. An archive is a JSON lines stream which starts with a
+// header record followed by the user, notion_page and mindmap records.
+const (
+	ArchiveRecordHeader     = "header"
+	ArchiveRecordUser       = "user"
+	ArchiveRecordNotionPage = "notion_page"
+	ArchiveRecordMindMap    = "mindmap"
+)
+
+const (
+	ImportModeMerge   = "merge"
+	ImportModeReplace = "replace"
+)
+
+// Archive is all the data owned by one user.
+type Archive struct {
+	Header  *ArchiveHeader
+	User    *UserProfile
+	Pages   []*NotionPage
+	MindMap *MindMapGraph
+}
+
+// Records returns the archive in the order it is written out.
+func (a *Archive) Records() []*ArchiveRecord {
+	records := make([]*ArchiveRecord, 0, len(a.Pages)+3)
+	records = append(records,
+		&ArchiveRecord{Type: ArchiveRecordHeader, Data: a.Header},
+		&ArchiveRecord{Type: ArchiveRecordUser, Data: a.User},
+	)
+	for _, p := range a.Pages {
+		records = append(records, &ArchiveRecord{Type: ArchiveRecordNotionPage, Data: p})
+	}
+	records = append(records, &ArchiveRecord{Type: ArchiveRecordMindMap, Data: a.MindMap})
+
+	return records
+}
+
+type ArchiveImportReport struct {
+	Mode                string `json:"mode"`
+	DeletedNotionPages  int    `json:"deleted_notion_pages"`
+	DeletedKeywordNodes int    `json:"deleted_keyword_nodes"`
+	NotionPages         int    `json:"notion_pages"`
+	SkippedNotionPages  int    `json:"skipped_notion_pages"`
+	KeywordNodes        int    `json:"keyword_nodes"`
+	KeywordEdges        int    `json:"keyword_edges"`
+}
+
+type ArchiveRecord struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+type ArchiveHeader struct {
+	Format     string    `json:"format"`
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	UserID     uuid.UUID `json:"user_id"`
+}
+
+// UserProfile is the part of a User which is safe to hand out, without the
+// notion tokens.
+type UserProfile struct {
+	ID           uuid.UUID `json:"id"`
+	Nickname     string    `json:"nickname"`
+	NotionUserID uuid.UUID `json:"notion_user_id"`
+}
+
+func (u *User) Profile() *UserProfile {
+	return &UserProfile{
+		ID:           u.ID,
+		Nickname:     u.Nickname,
+		NotionUserID: u.NotionUserID,
+	}
+}
@@ -0,0 +1,89 @@
+package domain
+
+import "time"
+
+// BackupFormatVersion 2 added the api keys. Version 1 backups are still read;
+// they restore without any api keys.
+const (
+	BackupFormat        = "notion-mindmap-backup"
+	BackupFormatVersion = 2
+)
+
+// Backup record types. A backup is a JSON lines stream of ArchiveRecord which
+// starts with a header record and ends with a checksum record covering every
+// line before it.
+const (
+	BackupRecordHeader      = "header"
+	BackupRecordUser        = "user"
+	BackupRecordNotionPage  = "notion_page"
+	BackupRecordKeywordNode = "keyword_node"
+	BackupRecordKeywordEdge = "keyword_edge"
+	BackupRecordAPIKey      = "api_key"
+	BackupRecordChecksum    = "checksum"
+)
+
+// Backup is the whole dataset of an installation, tokens included.
+type Backup struct {
+	Header       *BackupHeader
+	Users        []*User
+	NotionPages  []*NotionPage
+	KeywordNodes []*KeywordNode
+	KeywordEdges []*KeywordEdge
+	APIKeys      []*APIKey
+}
+
+type BackupHeader struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Driver    string    `json:"driver"`
+}
+
+type BackupChecksum struct {
+	Algorithm string `json:"algorithm"`
+	Sum       string `json:"sum"`
+	Records   int    `json:"records"`
+}
+
+type BackupReport struct {
+	Users        int `json:"users"`
+	NotionPages  int `json:"notion_pages"`
+	KeywordNodes int `json:"keyword_nodes"`
+	KeywordEdges int `json:"keyword_edges"`
+	APIKeys      int `json:"api_keys"`
+}
+
+func (b *Backup) Report() *BackupReport {
+	return &BackupReport{
+		Users:        len(b.Users),
+		NotionPages:  len(b.NotionPages),
+		KeywordNodes: len(b.KeywordNodes),
+		KeywordEdges: len(b.KeywordEdges),
+		APIKeys:      len(b.APIKeys),
+	}
+}
+
+// Records returns the backup without its checksum in the order it is written
+// out.
+func (b *Backup) Records() []*ArchiveRecord {
+	records := make([]*ArchiveRecord, 0,
+		1+len(b.Users)+len(b.NotionPages)+len(b.KeywordNodes)+len(b.KeywordEdges)+len(b.APIKeys))
+	records = append(records, &ArchiveRecord{Type: BackupRecordHeader, Data: b.Header})
+	for _, u := range b.Users {
+		records = append(records, &ArchiveRecord{Type: BackupRecordUser, Data: u.Record()})
+	}
+	for _, p := range b.NotionPages {
+		records = append(records, &ArchiveRecord{Type: BackupRecordNotionPage, Data: p})
+	}
+	for _, n := range b.KeywordNodes {
+		records = append(records, &ArchiveRecord{Type: BackupRecordKeywordNode, Data: n})
+	}
+	for _, e := range b.KeywordEdges {
+		records = append(records, &ArchiveRecord{Type: BackupRecordKeywordEdge, Data: e})
+	}
+	for _, k := range b.APIKeys {
+		records = append(records, &ArchiveRecord{Type: BackupRecordAPIKey, Data: k})
+	}
+
+	return records
+}
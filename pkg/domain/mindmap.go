@@ -9,6 +9,9 @@ type KeywordNode struct {
 	UserID       uuid.UUID `json:"user_id,omitempty"`
 	NotionPageID uuid.UUID `json:"notion_page_id,omitempty"`
 	Keyword      string    `json:"keyword"`
+	// Stale is set when the notion page changed after the keyword was
+	// extracted from it. Rebuilding the mind map replaces the node.
+	Stale bool `json:"stale,omitempty"`
 }
 
 type KeywordEdge struct {
@@ -1,6 +1,17 @@
 package domain
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sync states of a notion page. Pages uploaded by the client have none.
+const (
+	NotionPageSynced  = "synced"
+	NotionPageFailed  = "failed"
+	NotionPageRemoved = "removed"
+)
 
 type NotionPage struct {
 	ID           uuid.UUID `json:"id"`
@@ -9,4 +20,43 @@ type NotionPage struct {
 	NotionURL    string    `json:"notion_url"`
 	NotionPageID uuid.UUID `json:"notion_page_id"`
 	Summary      string    `json:"summary"`
+	// LastEditedAt is the last_edited_time notion reported when the content
+	// was last fetched, and LastSyncedAt when that happened.
+	LastEditedAt time.Time `json:"last_edited_at,omitzero"`
+	LastSyncedAt time.Time `json:"last_synced_at,omitzero"`
+	SyncStatus   string    `json:"sync_status,omitempty"`
+	SyncError    string    `json:"sync_error,omitempty"`
+	// Markdown and Outline keep the structure of synced pages which Content,
+	// their plain text, loses.
+	Markdown string               `json:"markdown,omitempty"`
+	Outline  []*NotionOutlineItem `json:"outline,omitempty"`
+}
+
+// NotionOutlineItem is a heading of a page with the headings nested below it.
+type NotionOutlineItem struct {
+	Level    int                  `json:"level"`
+	Text     string               `json:"text"`
+	BlockID  string               `json:"block_id"`
+	Children []*NotionOutlineItem `json:"children,omitempty"`
+}
+
+// NotionSyncReport counts what a sync of the notion pages of a user did.
+type NotionSyncReport struct {
+	Created   int                  `json:"created"`
+	Updated   int                  `json:"updated"`
+	Unchanged int                  `json:"unchanged"`
+	Removed   int                  `json:"removed"`
+	Failed    int                  `json:"failed"`
+	Failures  []*NotionSyncFailure `json:"failures,omitempty"`
+	// PrunedKeywordNodes were extracted from removed pages and taken out of
+	// the mind map.
+	PrunedKeywordNodes int `json:"pruned_keyword_nodes"`
+	// StaleKeywordNodes were extracted from updated pages and are flagged
+	// stale until the mind map is rebuilt.
+	StaleKeywordNodes int `json:"stale_keyword_nodes"`
+}
+
+type NotionSyncFailure struct {
+	NotionPageID string `json:"notion_page_id"`
+	Error        string `json:"error"`
 }
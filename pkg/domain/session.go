@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Session struct {
+	ID           string    `json:"-"`
+	UserID       uuid.UUID `json:"user_id"`
+	NotionUserID uuid.UUID `json:"notion_user_id"`
+	Token        *Token    `json:"token"`
+	UserAgent    string    `json:"user_agent"`
+	CreatedAt    time.Time `json:"created_at"`
+	LastSeenAt   time.Time `json:"last_seen_at"`
+
+	// APIKey is set when the request was authenticated with an api key rather
+	// than a login session. Such a session has no ID and no notion token.
+	APIKey *APIKey `json:"-"`
+}
+
+// Handle identifies the session in listings without revealing its ID, which
+// would be enough to take the session over.
+func (s *Session) Handle() string {
+	sum := sha256.Sum256([]byte(s.ID))
+	return hex.EncodeToString(sum[:16])
+}
+
+// SessionInfo is what a user sees about one of their sessions.
+type SessionInfo struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"`
+}
+
+func (s *Session) Info(currentID string) *SessionInfo {
+	return &SessionInfo{
+		ID:         s.Handle(),
+		UserAgent:  s.UserAgent,
+		CreatedAt:  s.CreatedAt,
+		LastSeenAt: s.LastSeenAt,
+		Current:    s.ID == currentID,
+	}
+}
+
+type Token struct {
+	AccessToken   string `json:"access_token"`
+	RefreshToken  string `json:"refresh_token"`
+	BotID         string `json:"bot_id"`
+	WorkspaceName string `json:"workspace_name"`
+}
+
+// String leaves the secrets out, so a token can be logged safely.
+func (t *Token) String() string {
+	return fmt.Sprintf("{BotID:%s WorkspaceName:%s}", t.BotID, t.WorkspaceName)
+}
+
+// AuthAttempt is one pass through the notion oauth flow. Its ID is kept in the
+// pre-auth cookie of the browser which started it, while State travels through
+// notion, so a callback is only accepted from that browser.
+type AuthAttempt struct {
+	ID        string    `json:"id"`
+	State     string    `json:"state"`
+	Verifier  string    `json:"verifier"`
+	ReturnTo  string    `json:"return_to"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
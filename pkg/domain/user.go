@@ -1,11 +1,127 @@
 package domain
 
-import "github.com/google/uuid"
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
 
 type User struct {
 	ID           uuid.UUID `json:"id,omitempty"`
 	Nickname     string    `json:"nickname"`
 	NotionUserID uuid.UUID `json:"notion_user_id"`
-	AccessToken  string    `json:"access_token"`
-	RefreshToken string    `json:"refresh_token"`
+	AccessToken  string    `json:"-"`
+	RefreshToken string    `json:"-"`
+	Role         string    `json:"role,omitempty"`
+	Disabled     bool      `json:"disabled,omitempty"`
+	// NotionDisconnected is set when notion refused to refresh the tokens and
+	// the user has to sign in with notion again.
+	NotionDisconnected bool `json:"notion_disconnected,omitempty"`
+	// NotionBotID is the bot notion created when the user authorized the
+	// integration. Webhook events name it among the ones with access.
+	NotionBotID string `json:"notion_bot_id,omitempty"`
+}
+
+// String leaves the notion tokens out, so a user can be logged safely.
+func (u *User) String() string {
+	return fmt.Sprintf("{ID:%s Nickname:%s NotionUserID:%s Role:%s Disabled:%t}",
+		u.ID, u.Nickname, u.NotionUserID, u.Role, u.Disabled)
+}
+
+// UserRecord is a User together with its notion tokens, as the storage
+// backends and backups keep it. It must never be written to an api response.
+type UserRecord struct {
+	*User
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (u *User) Record() *UserRecord {
+	return &UserRecord{
+		User:         u,
+		AccessToken:  u.AccessToken,
+		RefreshToken: u.RefreshToken,
+	}
+}
+
+// ToUser returns a copy of the recorded user with its notion tokens.
+func (r *UserRecord) ToUser() *User {
+	user := &User{}
+	if r.User != nil {
+		*user = *r.User
+	}
+	user.AccessToken = r.AccessToken
+	user.RefreshToken = r.RefreshToken
+
+	return user
+}
+
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin && !u.Disabled
+}
+
+func ValidRole(role string) bool {
+	return role == RoleUser || role == RoleAdmin
+}
+
+// UserAccount is what operators see about a user, without the notion tokens.
+type UserAccount struct {
+	ID           uuid.UUID `json:"id"`
+	Nickname     string    `json:"nickname"`
+	NotionUserID uuid.UUID `json:"notion_user_id"`
+	Role         string    `json:"role"`
+	Disabled     bool      `json:"disabled"`
+
+	NotionDisconnected bool `json:"notion_disconnected"`
+}
+
+func (u *User) Account() *UserAccount {
+	role := u.Role
+	if role == "" {
+		role = RoleUser
+	}
+
+	return &UserAccount{
+		ID:           u.ID,
+		Nickname:     u.Nickname,
+		NotionUserID: u.NotionUserID,
+		Role:         role,
+		Disabled:     u.Disabled,
+
+		NotionDisconnected: u.NotionDisconnected,
+	}
+}
+
+// UserUsage is how much data a user keeps on the server.
+type UserUsage struct {
+	UserID       uuid.UUID `json:"user_id"`
+	NotionPages  int       `json:"notion_pages"`
+	ContentBytes int       `json:"content_bytes"`
+	KeywordNodes int       `json:"keyword_nodes"`
+	KeywordEdges int       `json:"keyword_edges"`
+	Sessions     int       `json:"sessions"`
+}
+
+// AccountDeletionReport describes what was removed together with an account.
+type AccountDeletionReport struct {
+	UserID       uuid.UUID `json:"user_id"`
+	NotionPages  int       `json:"notion_pages"`
+	KeywordNodes int       `json:"keyword_nodes"`
+	KeywordEdges int       `json:"keyword_edges"`
+	APIKeys      int       `json:"api_keys"`
+	Sessions     int       `json:"sessions"`
+	// Failures lists the cleanups which failed after the account had already
+	// been deleted, so they have to be finished by an operator.
+	Failures []string `json:"failures,omitempty"`
+}
+
+// ReencryptReport counts the rows rewritten under the current encryption key.
+type ReencryptReport struct {
+	Users    int `json:"users"`
+	Sessions int `json:"sessions"`
 }
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS keyword_edges;
+DROP TABLE IF EXISTS keyword_nodes;
+DROP TABLE IF EXISTS notion_pages;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS users (
+	id             UUID PRIMARY KEY,
+	nickname       TEXT NOT NULL DEFAULT '',
+	notion_user_id UUID NOT NULL,
+	access_token   TEXT NOT NULL DEFAULT '',
+	refresh_token  TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS notion_pages (
+	id             UUID PRIMARY KEY,
+	user_id        UUID NOT NULL,
+	content        TEXT NOT NULL DEFAULT '',
+	notion_url     TEXT NOT NULL DEFAULT '',
+	notion_page_id UUID NOT NULL,
+	summary        TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS notion_pages_user_id_idx ON notion_pages (user_id);
+
+CREATE TABLE IF NOT EXISTS keyword_nodes (
+	id             UUID PRIMARY KEY,
+	user_id        UUID NOT NULL,
+	notion_page_id UUID NOT NULL,
+	keyword        TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS keyword_nodes_user_id_idx ON keyword_nodes (user_id);
+CREATE INDEX IF NOT EXISTS keyword_nodes_notion_page_id_idx ON keyword_nodes (notion_page_id);
+
+CREATE TABLE IF NOT EXISTS keyword_edges (
+	id       UUID PRIMARY KEY,
+	user_id  UUID NOT NULL,
+	keyword1 UUID NOT NULL,
+	keyword2 UUID NOT NULL
+);
+CREATE INDEX IF NOT EXISTS keyword_edges_user_id_idx ON keyword_edges (user_id);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS sessions (
+	id             TEXT PRIMARY KEY,
+	user_id        UUID NOT NULL,
+	notion_user_id UUID NOT NULL,
+	token          JSONB,
+	created_at     TIMESTAMPTZ NOT NULL,
+	last_seen_at   TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON sessions (user_id);
+CREATE INDEX IF NOT EXISTS sessions_last_seen_at_idx ON sessions (last_seen_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS users_notion_user_id_idx;
+ALTER TABLE users DROP COLUMN IF EXISTS disabled;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS notion_disconnected BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+	id           UUID PRIMARY KEY,
+	user_id      UUID NOT NULL,
+	name         TEXT NOT NULL DEFAULT '',
+	hint         TEXT NOT NULL DEFAULT '',
+	hash         TEXT NOT NULL UNIQUE,
+	scopes       TEXT[] NOT NULL DEFAULT '{}',
+	created_at   TIMESTAMPTZ NOT NULL,
+	last_used_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS api_keys_user_id_idx ON api_keys (user_id);
@@ -0,0 +1,4 @@
+ALTER TABLE notion_pages DROP COLUMN IF EXISTS sync_error;
+ALTER TABLE notion_pages DROP COLUMN IF EXISTS sync_status;
+ALTER TABLE notion_pages DROP COLUMN IF EXISTS last_synced_at;
+ALTER TABLE notion_pages DROP COLUMN IF EXISTS last_edited_at;
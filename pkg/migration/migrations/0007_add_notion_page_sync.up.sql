@@ -0,0 +1,4 @@
+ALTER TABLE notion_pages ADD COLUMN IF NOT EXISTS last_edited_at TIMESTAMPTZ;
+ALTER TABLE notion_pages ADD COLUMN IF NOT EXISTS last_synced_at TIMESTAMPTZ;
+ALTER TABLE notion_pages ADD COLUMN IF NOT EXISTS sync_status TEXT NOT NULL DEFAULT '';
+ALTER TABLE notion_pages ADD COLUMN IF NOT EXISTS sync_error TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS users_notion_bot_id_idx;
+ALTER TABLE users DROP COLUMN IF EXISTS notion_bot_id;
@@ -0,0 +1,2 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS notion_bot_id TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS users_notion_bot_id_idx ON users (notion_bot_id);
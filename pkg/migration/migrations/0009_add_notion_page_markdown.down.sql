@@ -0,0 +1,2 @@
+ALTER TABLE notion_pages DROP COLUMN IF EXISTS outline;
+ALTER TABLE notion_pages DROP COLUMN IF EXISTS markdown;
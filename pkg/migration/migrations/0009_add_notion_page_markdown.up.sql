@@ -0,0 +1,2 @@
+ALTER TABLE notion_pages ADD COLUMN IF NOT EXISTS markdown TEXT NOT NULL DEFAULT '';
+ALTER TABLE notion_pages ADD COLUMN IF NOT EXISTS outline JSONB;
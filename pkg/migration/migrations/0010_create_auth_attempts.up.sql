@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS auth_attempts (
+	id         TEXT PRIMARY KEY,
+	state      TEXT NOT NULL,
+	verifier   TEXT NOT NULL,
+	return_to  TEXT NOT NULL DEFAULT '',
+	expires_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS auth_attempts_expires_at_idx ON auth_attempts (expires_at);
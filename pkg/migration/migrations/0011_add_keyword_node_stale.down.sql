@@ -0,0 +1 @@
+ALTER TABLE keyword_nodes DROP COLUMN IF EXISTS stale;
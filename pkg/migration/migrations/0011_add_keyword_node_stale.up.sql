@@ -0,0 +1 @@
+ALTER TABLE keyword_nodes ADD COLUMN IF NOT EXISTS stale BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,124 @@
+// Package notion is a client of the notion api and its oauth token endpoint.
+package notion
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/config"
+)
+
+// maxErrorBody bounds how much of an error response is read.
+const maxErrorBody = 64 << 10
+
+// Client calls notion. Api calls take the access token of a user, while the
+// token endpoint is called with the integration credentials.
+type Client struct {
+	baseURL      *url.URL
+	version      string
+	clientID     string
+	clientSecret string
+	http         *http.Client
+}
+
+func NewClient(cfg *config.NotionConfig, oauth *config.OAuthConfig) (*Client, error) {
+	if oauth.ClientID == "" || oauth.ClientSecret == "" {
+		return nil, errors.New("required oauth config")
+	}
+
+	baseURL, err := url.Parse(strings.TrimSuffix(cfg.BaseURL, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if (baseURL.Scheme != "http" && baseURL.Scheme != "https") || baseURL.Host == "" {
+		return nil, fmt.Errorf("notion base url must be an absolute http(s) url: %s", cfg.BaseURL)
+	}
+	if cfg.Version == "" {
+		return nil, errors.New("required notion version")
+	}
+
+	timeout, err := time.ParseDuration(cfg.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		baseURL:      baseURL,
+		version:      cfg.Version,
+		clientID:     oauth.ClientID,
+		clientSecret: oauth.ClientSecret,
+		http:         &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// ClientID is the oauth client id of the integration, which the authorize url
+// carries.
+func (c *Client) ClientID() string {
+	return c.clientID
+}
+
+func bearer(accessToken string) string {
+	return "Bearer " + accessToken
+}
+
+func (c *Client) basic() string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(c.clientID+":"+c.clientSecret))
+}
+
+// do sends in as the json body of the request, if not nil, and decodes the
+// response into out. A response other than 200 OK is returned as *Error.
+func (c *Client) do(
+	ctx context.Context,
+	method, path string,
+	query url.Values,
+	authorization string,
+	in, out any,
+) error {
+	u := *c.baseURL
+	u.Path += path
+	u.RawQuery = query.Encode()
+
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Notion-Version", c.version)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return newError(resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode notion response of %s %s: %w", method, path, err)
+	}
+
+	return nil
+}
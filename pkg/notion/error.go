@@ -0,0 +1,88 @@
+package notion
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Error codes notion answers with. The token endpoint uses the oauth ones.
+const (
+	CodeUnauthorized       = "unauthorized"
+	CodeRestrictedResource = "restricted_resource"
+	CodeObjectNotFound     = "object_not_found"
+	CodeRateLimited        = "rate_limited"
+	CodeValidation         = "validation_error"
+	CodeInvalidGrant       = "invalid_grant"
+	CodeInvalidClient      = "invalid_client"
+)
+
+// Error is a response of notion other than 200 OK.
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	RequestID  string
+	// RetryAfter is how long notion asked to wait before the next request,
+	// set when it rate limits.
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("notion responded %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("notion responded %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+func newError(resp *http.Response) *Error {
+	e := &Error{StatusCode: resp.StatusCode}
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	body := struct {
+		Code             string `json:"code"`
+		Message          string `json:"message"`
+		RequestID        string `json:"request_id"`
+		OAuthError       string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		e.Message = string(data)
+	} else {
+		e.Code, e.Message, e.RequestID = body.Code, body.Message, body.RequestID
+		if e.Code == "" {
+			e.Code, e.Message = body.OAuthError, body.ErrorDescription
+		}
+	}
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		e.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	return e
+}
+
+func hasStatus(err error, statusCode int) bool {
+	var notionErr *Error
+	return errors.As(err, &notionErr) && notionErr.StatusCode == statusCode
+}
+
+// IsUnauthorized reports whether notion rejected the token of the request.
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsNotFound reports whether the object does not exist or is not shared with
+// the integration.
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsRateLimited reports whether notion throttled the request.
+func IsRateLimited(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests)
+}
@@ -0,0 +1,423 @@
+package notion
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// BlockNode is a block together with its children, read down to some depth.
+type BlockNode struct {
+	*Block
+	Children []*BlockNode
+}
+
+// OutlineItem is a heading of a page with the headings nested below it.
+type OutlineItem struct {
+	Level    int            `json:"level"`
+	Text     string         `json:"text"`
+	BlockID  string         `json:"block_id"`
+	Children []*OutlineItem `json:"children,omitempty"`
+}
+
+var headingLevels = map[string]int{
+	"heading_1": 1,
+	"heading_2": 2,
+	"heading_3": 3,
+}
+
+// Markdown renders the blocks of a page as markdown under a level one heading
+// holding title, if there is one. Blocks markdown has no syntax for, such as
+// toggles, are written as html; unsupported blocks are left out.
+func Markdown(title string, blocks []*BlockNode) string {
+	var b strings.Builder
+	if title != "" {
+		b.WriteString("# " + escapeMarkdown(title) + "\n\n")
+	}
+	b.WriteString(renderBlocks(blocks))
+
+	return strings.TrimSpace(b.String()) + "\n"
+}
+
+// Outline returns the headings of the blocks nested by level. Headings inside
+// toggles, columns and other containers are included in document order.
+func Outline(blocks []*BlockNode) []*OutlineItem {
+	var roots []*OutlineItem
+	var stack []*OutlineItem
+
+	var walk func(nodes []*BlockNode)
+	walk = func(nodes []*BlockNode) {
+		for _, node := range nodes {
+			if level, ok := headingLevels[node.Type]; ok {
+				item := &OutlineItem{
+					Level:   level,
+					Text:    BlockText(node.Block),
+					BlockID: node.ID,
+				}
+				for len(stack) > 0 && stack[len(stack)-1].Level >= level {
+					stack = stack[:len(stack)-1]
+				}
+				if len(stack) == 0 {
+					roots = append(roots, item)
+				} else {
+					parent := stack[len(stack)-1]
+					parent.Children = append(parent.Children, item)
+				}
+				stack = append(stack, item)
+			}
+			walk(node.Children)
+		}
+	}
+	walk(blocks)
+
+	return roots
+}
+
+// renderBlocks renders sibling blocks, a blank line apart except for the
+// items of one list.
+func renderBlocks(nodes []*BlockNode) string {
+	var b strings.Builder
+	number := 0
+	prevType := ""
+
+	for _, node := range nodes {
+		if node.Type == "numbered_list_item" && prevType == "numbered_list_item" {
+			number++
+		} else {
+			number = 1
+		}
+
+		rendered := renderBlock(node, number)
+		if rendered == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			if isListItem(node.Type) && node.Type == prevType {
+				b.WriteString("\n")
+			} else {
+				b.WriteString("\n\n")
+			}
+		}
+		b.WriteString(rendered)
+		prevType = node.Type
+	}
+
+	return b.String()
+}
+
+func isListItem(blockType string) bool {
+	return blockType == "bulleted_list_item" || blockType == "numbered_list_item" || blockType == "to_do"
+}
+
+func renderBlock(node *BlockNode, number int) string {
+	content := node.content()
+	text := renderRichText(content.RichText)
+	children := renderBlocks(node.Children)
+
+	switch node.Type {
+	case "paragraph":
+		return joinChildren(text, children)
+	case "heading_1", "heading_2", "heading_3":
+		heading := strings.Repeat("#", headingLevels[node.Type]+1) + " " + text
+		return joinChildren(heading, children)
+	case "bulleted_list_item":
+		return listItem("- "+text, children, "  ")
+	case "numbered_list_item":
+		marker := fmt.Sprintf("%d. ", number)
+		return listItem(marker+text, children, strings.Repeat(" ", len(marker)))
+	case "to_do":
+		box := "[ ] "
+		if content.Checked {
+			box = "[x] "
+		}
+		return listItem("- "+box+text, children, "  ")
+	case "toggle":
+		return "<details>\n<summary>" + text + "</summary>\n\n" + children + "\n\n</details>"
+	case "quote":
+		return prefixLines(joinChildren(text, children), "> ")
+	case "callout":
+		if content.Icon != nil && content.Icon.Emoji != "" {
+			text = content.Icon.Emoji + " " + text
+		}
+		return prefixLines(joinChildren(text, children), "> ")
+	case "code":
+		code := PlainText(content.RichText)
+		fence := strings.Repeat("`", max(3, longestRun(code, '`')+1))
+		language := content.Language
+		if strings.ContainsAny(language, "`\r\n") {
+			language = ""
+		}
+		return fence + language + "\n" + code + "\n" + fence
+	case "equation":
+		return "$$\n" + content.Expression + "\n$$"
+	case "divider":
+		return "---"
+	case "image":
+		alt := escapeMarkdown(PlainText(content.Caption))
+		href := linkURL(content.fileURL())
+		if href == "" {
+			return alt
+		}
+		return "![" + alt + "](" + href + ")"
+	case "video", "audio", "file", "pdf", "bookmark", "embed", "link_preview":
+		label := renderRichText(content.Caption)
+		if label == "" {
+			label = escapeMarkdown(content.Name)
+		}
+		if label == "" {
+			label = escapeMarkdown(content.fileURL())
+		}
+		href := linkURL(content.fileURL())
+		if href == "" {
+			return label
+		}
+		return "[" + label + "](" + href + ")"
+	case "child_page", "child_database":
+		return "[" + escapeMarkdown(content.Title) + "](" + pageURL(node.ID) + ")"
+	case "table":
+		return renderTable(node)
+	case "column_list", "column", "synced_block":
+		return children
+	default:
+		return ""
+	}
+}
+
+func joinChildren(text, children string) string {
+	if children == "" {
+		return text
+	}
+	if text == "" {
+		return children
+	}
+	return text + "\n\n" + children
+}
+
+func listItem(line, children, indent string) string {
+	if children == "" {
+		return line
+	}
+	return line + "\n" + prefixLines(children, indent)
+}
+
+// prefixLines puts prefix in front of every line of s. Empty lines only get
+// it if the prefix is not just indentation, so quotes stay continuous.
+func prefixLines(s, prefix string) string {
+	if prefix == "" {
+		return s
+	}
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line == "" && strings.TrimSpace(prefix) == "" {
+			continue
+		}
+		lines[i] = strings.TrimRight(prefix+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderTable renders the table_row children of a table. Markdown tables need
+// a header, so the first row is used as one even if notion does not mark it.
+func renderTable(node *BlockNode) string {
+	var rows [][]string
+	for _, child := range node.Children {
+		if child.Type != "table_row" {
+			continue
+		}
+		var cells []string
+		for _, cell := range child.content().Cells {
+			cells = append(cells, escapeTablePipes(renderRichText(cell)))
+		}
+		rows = append(rows, cells)
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for i, row := range rows {
+		b.WriteString("| " + strings.Join(row, " | ") + " |")
+		if i == 0 {
+			b.WriteString("\n|" + strings.Repeat(" --- |", len(row)))
+		}
+		if i < len(rows)-1 {
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
+
+func pageURL(id string) string {
+	return "https://www.notion.so/" + strings.ReplaceAll(id, "-", "")
+}
+
+// renderRichText renders the spans with their annotations and links.
+func renderRichText(spans []RichText) string {
+	var b strings.Builder
+	for _, span := range spans {
+		b.WriteString(renderSpan(span))
+	}
+	return b.String()
+}
+
+func renderSpan(span RichText) string {
+	if span.Type == "equation" && span.Equation != nil {
+		return "$" + span.Equation.Expression + "$"
+	}
+
+	text := span.PlainText
+	if text == "" {
+		return ""
+	}
+
+	// markers must touch the text, so surrounding spaces go outside them
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	lead := text[:strings.Index(text, trimmed)]
+	trail := text[len(lead)+len(trimmed):]
+
+	a := span.Annotations
+	if a != nil && a.Code {
+		trimmed = codeSpan(trimmed)
+	} else {
+		trimmed = escapeMarkdown(trimmed)
+	}
+	if a != nil {
+		if a.Bold {
+			trimmed = "**" + trimmed + "**"
+		}
+		if a.Italic {
+			trimmed = "_" + trimmed + "_"
+		}
+		if a.Strikethrough {
+			trimmed = "~~" + trimmed + "~~"
+		}
+	}
+	if href := linkURL(span.Href); href != "" {
+		trimmed = "[" + trimmed + "](" + href + ")"
+	}
+
+	return lead + trimmed + trail
+}
+
+// codeSpan wraps s in more backticks than it has in a row, padded with spaces
+// when s starts or ends with one.
+func codeSpan(s string) string {
+	ticks := strings.Repeat("`", longestRun(s, '`')+1)
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return ticks + s + ticks
+}
+
+// longestRun returns the length of the longest run of c in s.
+func longestRun(s string, c byte) int {
+	longest, run := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != c {
+			run = 0
+			continue
+		}
+		run++
+		longest = max(longest, run)
+	}
+	return longest
+}
+
+var linkSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+// urlEscaper encodes what would end a markdown link destination early.
+var urlEscaper = strings.NewReplacer(
+	" ", "%20",
+	"(", "%28",
+	")", "%29",
+)
+
+// linkURL returns raw ready to be a markdown link destination, or "" if it is
+// not an http, https or mailto url. Notion links pages of the workspace by
+// path, so a path is taken relative to notion.
+func linkURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(raw, "/") && !strings.HasPrefix(raw, "//") {
+		raw = "https://www.notion.so" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || !linkSchemes[strings.ToLower(u.Scheme)] {
+		return ""
+	}
+	if u.Scheme != "mailto" && u.Host == "" {
+		return ""
+	}
+
+	return urlEscaper.Replace(raw)
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	`*`, `\*`,
+	`_`, `\_`,
+	`[`, `\[`,
+	`]`, `\]`,
+	`<`, `\<`,
+	`|`, `\|`,
+)
+
+// escapeMarkdown escapes the characters which markdown would read as syntax,
+// and the markers which would start a heading, quote or list at the start of a
+// line. Escaping a marker which is not at the start of a line is harmless.
+func escapeMarkdown(s string) string {
+	lines := strings.Split(markdownEscaper.Replace(s), "\n")
+	for i, line := range lines {
+		lines[i] = escapeLineStart(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func escapeLineStart(line string) string {
+	rest := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(rest)]
+	if rest == "" {
+		return line
+	}
+
+	switch rest[0] {
+	case '#', '>', '-', '+':
+		return indent + `\` + rest
+	}
+
+	digits := len(rest) - len(strings.TrimLeft(rest, "0123456789"))
+	if digits > 0 && digits < len(rest) && (rest[digits] == '.' || rest[digits] == ')') {
+		return indent + rest[:digits] + `\` + rest[digits:]
+	}
+
+	return line
+}
+
+// escapeTablePipes escapes the pipes of a table cell which are not escaped
+// yet, such as those in code spans, so they do not split the cell.
+func escapeTablePipes(s string) string {
+	var b strings.Builder
+	backslashes := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '|' && backslashes%2 == 0 {
+			b.WriteByte('\\')
+		}
+		if s[i] == '\\' {
+			backslashes++
+		} else {
+			backslashes = 0
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
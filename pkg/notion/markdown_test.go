@@ -0,0 +1,172 @@
+package notion
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRenderSpanLinks(t *testing.T) {
+	tests := []struct {
+		name string
+		href string
+		want string
+	}{
+		{"https", "https://example.com/a", "[text](https://example.com/a)"},
+		{"http", "http://example.com", "[text](http://example.com)"},
+		{"mailto", "mailto:me@example.com", "[text](mailto:me@example.com)"},
+		{"upper case scheme", "HTTPS://example.com", "[text](HTTPS://example.com)"},
+		{"notion path", "/0123abcd", "[text](https://www.notion.so/0123abcd)"},
+		{"parens and spaces", "https://example.com/a (b)", "[text](https://example.com/a%20%28b%29)"},
+		{"closing paren breakout", "https://example.com/)[x](javascript:alert(1)", "[text](https://example.com/%29[x]%28javascript:alert%281%29)"},
+		{"javascript", "javascript:alert(1)", "text"},
+		{"data", "data:text/html,<script>", "text"},
+		{"vbscript", "vbscript:msgbox", "text"},
+		{"protocol relative", "//evil.example.com", "text"},
+		{"no host", "https:///path", "text"},
+		{"no href", "", "text"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderSpan(RichText{PlainText: "text", Href: tt.href})
+			if got != tt.want {
+				t.Fatalf("renderSpan with href %q = %q, want %q", tt.href, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderSpanAnnotations(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		annotations *Annotations
+		want        string
+	}{
+		{"plain", "plain", nil, "plain"},
+		{"spaces stay outside markers", " bold ", &Annotations{Bold: true}, " **bold** "},
+		{"code", "a := b", &Annotations{Code: true}, "`a := b`"},
+		{"code with backtick", "a`b", &Annotations{Code: true}, "``a`b``"},
+		{"code with backtick run", "a``b", &Annotations{Code: true}, "```a``b```"},
+		{"code starting with backtick", "`a", &Annotations{Code: true}, "`` `a ``"},
+		{"escaped inside markers", "*x*", &Annotations{Italic: true}, `_\*x\*_`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderSpan(RichText{PlainText: tt.text, Annotations: tt.annotations})
+			if got != tt.want {
+				t.Fatalf("renderSpan(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "just text", "just text"},
+		{"inline syntax", "a*b_c`d[e]<f>|g\\h", `a\*b\_c\` + "`" + `d\[e\]\<f>\|g\\h`},
+		{"heading", "# not a heading", `\# not a heading`},
+		{"quote", "> not a quote", `\> not a quote`},
+		{"dash list", "- not a list", `\- not a list`},
+		{"plus list", "+ not a list", `\+ not a list`},
+		{"numbered list", "1. not a list", `1\. not a list`},
+		{"numbered list with paren", "12) not a list", `12\) not a list`},
+		{"thematic break", "---", `\---`},
+		{"indented marker", "  # indented", `  \# indented`},
+		{"marker on a later line", "first\n# second\n3. third", "first\n\\# second\n3\\. third"},
+		{"marker inside a line", "a - b # c", "a - b # c"},
+		{"number without marker", "2025 was a year", "2025 was a year"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeMarkdown(tt.in); got != tt.want {
+				t.Fatalf("escapeMarkdown(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownBlocks(t *testing.T) {
+	tests := []struct {
+		name  string
+		block string
+		want  string
+	}{
+		{
+			name:  "code fence",
+			block: `{"type":"code","code":{"language":"go","rich_text":[{"plain_text":"x := 1"}]}}`,
+			want:  "```go\nx := 1\n```\n",
+		},
+		{
+			name:  "code fence longer than the content fences",
+			block: `{"type":"code","code":{"language":"markdown","rich_text":[{"plain_text":"` + "```go\\n````\\n```" + `"}]}}`,
+			want:  "`````markdown\n```go\n````\n```\n`````\n",
+		},
+		{
+			name:  "code language with backtick",
+			block: `{"type":"code","code":{"language":"go` + "`" + `","rich_text":[{"plain_text":"x"}]}}`,
+			want:  "```\nx\n```\n",
+		},
+		{
+			name:  "image with javascript url",
+			block: `{"type":"image","image":{"caption":[{"plain_text":"alt"}],"external":{"url":"javascript:alert(1)"}}}`,
+			want:  "alt\n",
+		},
+		{
+			name:  "image url with parens",
+			block: `{"type":"image","image":{"caption":[],"file":{"url":"https://files.example.com/a (1).png"}}}`,
+			want:  "![](https://files.example.com/a%20%281%29.png)\n",
+		},
+		{
+			name:  "bookmark with data url",
+			block: `{"type":"bookmark","bookmark":{"caption":[],"url":"data:text/html,x"}}`,
+			want:  "data:text/html,x\n",
+		},
+		{
+			name:  "paragraph starting with a marker",
+			block: `{"type":"paragraph","paragraph":{"rich_text":[{"plain_text":"# hash"}]}}`,
+			want:  "\\# hash\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block := &Block{}
+			if err := json.Unmarshal([]byte(tt.block), block); err != nil {
+				t.Fatal(err)
+			}
+			if got := Markdown("", []*BlockNode{{Block: block}}); got != tt.want {
+				t.Fatalf("Markdown = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkdownTablePipes(t *testing.T) {
+	row := func(cells string) *BlockNode {
+		block := &Block{}
+		if err := json.Unmarshal([]byte(`{"type":"table_row","table_row":{"cells":`+cells+`}}`), block); err != nil {
+			t.Fatal(err)
+		}
+		return &BlockNode{Block: block}
+	}
+
+	table := &BlockNode{
+		Block: &Block{Type: "table"},
+		Children: []*BlockNode{
+			row(`[[{"plain_text":"a|b"}],[{"plain_text":"x|y","annotations":{"code":true}}]]`),
+			row(`[[{"plain_text":"1"}],[{"plain_text":"2"}]]`),
+		},
+	}
+
+	want := "| a\\|b | `x\\|y` |\n| --- | --- |\n| 1 | 2 |\n"
+	if got := Markdown("", []*BlockNode{table}); got != want {
+		t.Fatalf("Markdown = %q, want %q", got, want)
+	}
+}
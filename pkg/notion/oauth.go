@@ -0,0 +1,50 @@
+package notion
+
+import (
+	"context"
+	"net/http"
+)
+
+// Token is the answer of the token endpoint.
+type Token struct {
+	AccessToken   string `json:"access_token"`
+	RefreshToken  string `json:"refresh_token"`
+	BotID         string `json:"bot_id"`
+	WorkspaceID   string `json:"workspace_id"`
+	WorkspaceName string `json:"workspace_name"`
+	Owner         struct {
+		Type string `json:"type"`
+		User *User  `json:"user"`
+	} `json:"owner"`
+}
+
+// ExchangeCode trades the authorization code of the oauth callback for a
+// token. redirectURI and codeVerifier must be the ones of the authorize url.
+func (c *Client) ExchangeCode(
+	ctx context.Context,
+	code, redirectURI, codeVerifier string,
+) (*Token, error) {
+	return c.grantToken(ctx, map[string]string{
+		"grant_type":    "authorization_code",
+		"code":          code,
+		"redirect_uri":  redirectURI,
+		"code_verifier": codeVerifier,
+	})
+}
+
+// RefreshToken exchanges the refresh token for a new token pair.
+func (c *Client) RefreshToken(ctx context.Context, refreshToken string) (*Token, error) {
+	return c.grantToken(ctx, map[string]string{
+		"grant_type":    "refresh_token",
+		"refresh_token": refreshToken,
+	})
+}
+
+func (c *Client) grantToken(ctx context.Context, payload map[string]string) (*Token, error) {
+	tok := &Token{}
+	if err := c.do(ctx, http.MethodPost, "/v1/oauth/token", nil, c.basic(), payload, tok); err != nil {
+		return nil, err
+	}
+
+	return tok, nil
+}
@@ -0,0 +1,122 @@
+package notion
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+type RichText struct {
+	Type        string       `json:"type"`
+	PlainText   string       `json:"plain_text"`
+	Href        string       `json:"href,omitempty"`
+	Annotations *Annotations `json:"annotations,omitempty"`
+	Text        *struct {
+		Content string `json:"content"`
+		Link    *struct {
+			URL string `json:"url"`
+		} `json:"link"`
+	} `json:"text,omitempty"`
+	Equation *struct {
+		Expression string `json:"expression"`
+	} `json:"equation,omitempty"`
+}
+
+type Annotations struct {
+	Bold          bool   `json:"bold"`
+	Italic        bool   `json:"italic"`
+	Strikethrough bool   `json:"strikethrough"`
+	Underline     bool   `json:"underline"`
+	Code          bool   `json:"code"`
+	Color         string `json:"color"`
+}
+
+// PlainText joins the plain text of the rich text spans.
+func PlainText(spans []RichText) string {
+	var b strings.Builder
+	for _, s := range spans {
+		b.WriteString(s.PlainText)
+	}
+	return b.String()
+}
+
+type Parent struct {
+	Type       string `json:"type"`
+	PageID     string `json:"page_id,omitempty"`
+	DatabaseID string `json:"database_id,omitempty"`
+	BlockID    string `json:"block_id,omitempty"`
+	Workspace  bool   `json:"workspace,omitempty"`
+}
+
+// Page is a page, or a database which search and the database endpoint
+// return in the same shape with a Title instead of a title property.
+type Page struct {
+	Object         string                     `json:"object"`
+	ID             string                     `json:"id"`
+	CreatedTime    time.Time                  `json:"created_time"`
+	LastEditedTime time.Time                  `json:"last_edited_time"`
+	Archived       bool                       `json:"archived"`
+	InTrash        bool                       `json:"in_trash"`
+	URL            string                     `json:"url"`
+	Parent         Parent                     `json:"parent"`
+	Properties     map[string]json.RawMessage `json:"properties"`
+	Title          []RichText                 `json:"title,omitempty"`
+}
+
+// PageTitle returns the title of a database, or the value of the title
+// property of a page.
+func (p *Page) PageTitle() string {
+	if len(p.Title) > 0 {
+		return PlainText(p.Title)
+	}
+
+	for _, raw := range p.Properties {
+		prop := struct {
+			Type  string     `json:"type"`
+			Title []RichText `json:"title"`
+		}{}
+		if err := json.Unmarshal(raw, &prop); err == nil && prop.Type == "title" {
+			return PlainText(prop.Title)
+		}
+	}
+
+	return ""
+}
+
+// Block is a block of page content. Content holds the object under the key
+// named by Type, such as the rich text of a paragraph, to be decoded by the
+// caller which knows the type.
+type Block struct {
+	Object         string          `json:"object"`
+	ID             string          `json:"id"`
+	Type           string          `json:"type"`
+	HasChildren    bool            `json:"has_children"`
+	CreatedTime    time.Time       `json:"created_time"`
+	LastEditedTime time.Time       `json:"last_edited_time"`
+	Archived       bool            `json:"archived"`
+	InTrash        bool            `json:"in_trash"`
+	Parent         Parent          `json:"parent"`
+	Content        json.RawMessage `json:"-"`
+}
+
+func (b *Block) UnmarshalJSON(data []byte) error {
+	type block Block
+	if err := json.Unmarshal(data, (*block)(b)); err != nil {
+		return err
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	b.Content = fields[b.Type]
+
+	return nil
+}
+
+// List is a page of results. The next one starts at NextCursor while HasMore.
+type List[T any] struct {
+	Results    []T    `json:"results"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor"`
+}
@@ -0,0 +1,150 @@
+package notion
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// MaxPageSize is the most results notion returns at once.
+const MaxPageSize = 100
+
+// SearchParams narrows a search. Filter is "page" or "database" to return one
+// kind of object only.
+type SearchParams struct {
+	Query       string
+	Filter      string
+	StartCursor string
+	PageSize    int
+}
+
+// Search finds the pages and databases shared with the integration, the most
+// recently edited first.
+func (c *Client) Search(ctx context.Context, accessToken string, params *SearchParams) (*List[*Page], error) {
+	body := map[string]any{
+		"sort": map[string]string{
+			"direction": "descending",
+			"timestamp": "last_edited_time",
+		},
+	}
+	if params.Query != "" {
+		body["query"] = params.Query
+	}
+	if params.Filter != "" {
+		body["filter"] = map[string]string{"property": "object", "value": params.Filter}
+	}
+	if params.StartCursor != "" {
+		body["start_cursor"] = params.StartCursor
+	}
+	if params.PageSize > 0 {
+		body["page_size"] = params.PageSize
+	}
+
+	list := &List[*Page]{}
+	if err := c.do(ctx, http.MethodPost, "/v1/search", nil, bearer(accessToken), body, list); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (c *Client) GetPage(ctx context.Context, accessToken, pageID string) (*Page, error) {
+	page := &Page{}
+	path := "/v1/pages/" + url.PathEscape(pageID)
+	if err := c.do(ctx, http.MethodGet, path, nil, bearer(accessToken), nil, page); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// GetBlockChildren returns one page of the children of a block or page.
+func (c *Client) GetBlockChildren(
+	ctx context.Context,
+	accessToken, blockID, startCursor string,
+	pageSize int,
+) (*List[*Block], error) {
+	query := url.Values{}
+	if startCursor != "" {
+		query.Set("start_cursor", startCursor)
+	}
+	if pageSize > 0 {
+		query.Set("page_size", strconv.Itoa(pageSize))
+	}
+
+	list := &List[*Block]{}
+	path := "/v1/blocks/" + url.PathEscape(blockID) + "/children"
+	if err := c.do(ctx, http.MethodGet, path, query, bearer(accessToken), nil, list); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// ListBlockChildren returns all children of a block or page, following the
+// cursor through every page of results. Nested children are not fetched.
+func (c *Client) ListBlockChildren(ctx context.Context, accessToken, blockID string) ([]*Block, error) {
+	var blocks []*Block
+	cursor := ""
+	for {
+		list, err := c.GetBlockChildren(ctx, accessToken, blockID, cursor, MaxPageSize)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, list.Results...)
+
+		if !list.HasMore {
+			return blocks, nil
+		}
+		cursor = list.NextCursor
+	}
+}
+
+func (c *Client) GetDatabase(ctx context.Context, accessToken, databaseID string) (*Page, error) {
+	database := &Page{}
+	path := "/v1/databases/" + url.PathEscape(databaseID)
+	if err := c.do(ctx, http.MethodGet, path, nil, bearer(accessToken), nil, database); err != nil {
+		return nil, err
+	}
+
+	return database, nil
+}
+
+// QueryParams selects the rows of a database query. Filter and Sorts are
+// passed to notion as they are.
+type QueryParams struct {
+	Filter      any
+	Sorts       any
+	StartCursor string
+	PageSize    int
+}
+
+// QueryDatabase returns one page of the rows of a database.
+func (c *Client) QueryDatabase(
+	ctx context.Context,
+	accessToken, databaseID string,
+	params *QueryParams,
+) (*List[*Page], error) {
+	body := map[string]any{}
+	if params.Filter != nil {
+		body["filter"] = params.Filter
+	}
+	if params.Sorts != nil {
+		body["sorts"] = params.Sorts
+	}
+	if params.StartCursor != "" {
+		body["start_cursor"] = params.StartCursor
+	}
+	if params.PageSize > 0 {
+		body["page_size"] = params.PageSize
+	}
+
+	list := &List[*Page]{}
+	path := "/v1/databases/" + url.PathEscape(databaseID) + "/query"
+	if err := c.do(ctx, http.MethodPost, path, nil, bearer(accessToken), body, list); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
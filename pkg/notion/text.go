@@ -0,0 +1,76 @@
+package notion
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// blockContent holds the fields of block contents which carry text. Which of
+// them are set depends on the block type.
+type blockContent struct {
+	RichText   []RichText   `json:"rich_text"`
+	Title      string       `json:"title"`
+	Expression string       `json:"expression"`
+	URL        string       `json:"url"`
+	Cells      [][]RichText `json:"cells"`
+	Caption    []RichText   `json:"caption"`
+	Checked    bool         `json:"checked"`
+	Language   string       `json:"language"`
+	Name       string       `json:"name"`
+	Icon       *struct {
+		Emoji string `json:"emoji"`
+	} `json:"icon"`
+	// External and File hold the url of media blocks, depending on where
+	// the file is hosted.
+	External *struct {
+		URL string `json:"url"`
+	} `json:"external"`
+	File *struct {
+		URL string `json:"url"`
+	} `json:"file"`
+}
+
+// fileURL returns the url of a media block.
+func (c *blockContent) fileURL() string {
+	switch {
+	case c.External != nil:
+		return c.External.URL
+	case c.File != nil:
+		return c.File.URL
+	default:
+		return c.URL
+	}
+}
+
+func (b *Block) content() *blockContent {
+	content := &blockContent{}
+	if len(b.Content) > 0 {
+		_ = json.Unmarshal(b.Content, content)
+	}
+	return content
+}
+
+// BlockText returns the plain text of the block without its children, or ""
+// for blocks without text such as dividers.
+func BlockText(b *Block) string {
+	content := b.content()
+
+	switch {
+	case len(content.RichText) > 0:
+		return PlainText(content.RichText)
+	case content.Title != "":
+		return content.Title
+	case content.Expression != "":
+		return content.Expression
+	case len(content.Cells) > 0:
+		cells := make([]string, len(content.Cells))
+		for i, cell := range content.Cells {
+			cells[i] = PlainText(cell)
+		}
+		return strings.Join(cells, " | ")
+	case len(content.Caption) > 0:
+		return PlainText(content.Caption)
+	default:
+		return content.URL
+	}
+}
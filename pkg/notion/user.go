@@ -0,0 +1,37 @@
+package notion
+
+import (
+	"context"
+	"net/http"
+)
+
+type User struct {
+	Object    string `json:"object"`
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	AvatarURL string `json:"avatar_url,omitempty"`
+	Type      string `json:"type"`
+	Person    *struct {
+		Email string `json:"email"`
+	} `json:"person,omitempty"`
+	Bot *Bot `json:"bot,omitempty"`
+}
+
+type Bot struct {
+	Owner struct {
+		Type string `json:"type"`
+		User *User  `json:"user"`
+	} `json:"owner"`
+	WorkspaceName string `json:"workspace_name"`
+}
+
+// Me returns the bot user of the access token, whose owner is the user who
+// authorized it.
+func (c *Client) Me(ctx context.Context, accessToken string) (*User, error) {
+	user := &User{}
+	if err := c.do(ctx, http.MethodGet, "/v1/users/me", nil, bearer(accessToken), nil, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
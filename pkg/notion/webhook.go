@@ -0,0 +1,72 @@
+package notion
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// SignatureHeader carries the signature of a webhook delivery.
+const SignatureHeader = "X-Notion-Signature"
+
+// Types of the webhook events on pages.
+const (
+	EventPageCreated           = "page.created"
+	EventPageContentUpdated    = "page.content_updated"
+	EventPagePropertiesUpdated = "page.properties_updated"
+	EventPageMoved             = "page.moved"
+	EventPageUndeleted         = "page.undeleted"
+	EventPageDeleted           = "page.deleted"
+)
+
+// WebhookEvent is a delivery of a webhook subscription. The first delivery
+// only carries the VerificationToken, which signs the later ones.
+type WebhookEvent struct {
+	ID                string    `json:"id"`
+	Timestamp         time.Time `json:"timestamp"`
+	WorkspaceID       string    `json:"workspace_id"`
+	SubscriptionID    string    `json:"subscription_id"`
+	IntegrationID     string    `json:"integration_id"`
+	Type              string    `json:"type"`
+	AttemptNumber     int       `json:"attempt_number"`
+	VerificationToken string    `json:"verification_token,omitempty"`
+	Entity            struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+	} `json:"entity"`
+	AccessibleBy []struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+	} `json:"accessible_by"`
+}
+
+// BotIDs returns the bots which have access to the entity of the event.
+func (e *WebhookEvent) BotIDs() []string {
+	var ids []string
+	for _, a := range e.AccessibleBy {
+		if a.Type == "bot" {
+			ids = append(ids, a.ID)
+		}
+	}
+	return ids
+}
+
+// VerifySignature reports whether signature, the value of SignatureHeader, is
+// the HMAC-SHA256 of body keyed with the verification token of the
+// subscription.
+func VerifySignature(verificationToken string, body []byte, signature string) bool {
+	sum, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok || verificationToken == "" {
+		return false
+	}
+	got, err := hex.DecodeString(sum)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(verificationToken))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
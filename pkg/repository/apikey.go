@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+)
+
+type MemoryAPIKeyRepo struct {
+	txManager *MemoryTxManager
+	keys      memoryTable[domain.APIKey]
+}
+
+func NewMemoryAPIKeyRepo(txManager *MemoryTxManager) *MemoryAPIKeyRepo {
+	return &MemoryAPIKeyRepo{
+		txManager: txManager,
+		keys:      newMemoryTable[domain.APIKey](txManager, "api_keys"),
+	}
+}
+
+func (r *MemoryAPIKeyRepo) BeginTransaction(ctx context.Context) (context.Context, error) {
+	return r.txManager.BeginTransaction(ctx)
+}
+
+func (r *MemoryAPIKeyRepo) Commit(ctx context.Context) error {
+	return r.txManager.Commit(ctx)
+}
+
+func (r *MemoryAPIKeyRepo) Abort(ctx context.Context) {
+	r.txManager.Abort(ctx)
+}
+
+func (r *MemoryAPIKeyRepo) CreateAPIKey(ctx context.Context, key *domain.APIKey) (*domain.APIKey, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	key.ID = id
+
+	var created *domain.APIKey
+	err = r.txManager.update(ctx, func(tx *memoryTx) error {
+		created = r.keys.put(tx, id, key)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (r *MemoryAPIKeyRepo) FindAPIKeyByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	keys, err := r.keys.list(ctx, func(k *domain.APIKey) bool {
+		return k.Hash == hash
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w api key", ErrNotFound)
+	}
+
+	return keys[0], nil
+}
+
+func (r *MemoryAPIKeyRepo) ListAPIKeysByUser(ctx context.Context, userID uuid.UUID) ([]*domain.APIKey, error) {
+	return r.keys.list(ctx, func(k *domain.APIKey) bool {
+		return k.UserID == userID
+	})
+}
+
+func (r *MemoryAPIKeyRepo) ListAPIKeys(ctx context.Context) ([]*domain.APIKey, error) {
+	return r.keys.list(ctx, func(*domain.APIKey) bool {
+		return true
+	})
+}
+
+// RestoreAPIKeys stores the keys under their own ids.
+func (r *MemoryAPIKeyRepo) RestoreAPIKeys(ctx context.Context, keys ...*domain.APIKey) error {
+	return r.txManager.update(ctx, func(tx *memoryTx) error {
+		for _, key := range keys {
+			r.keys.put(tx, key.ID, key)
+		}
+		return nil
+	})
+}
+
+func (r *MemoryAPIKeyRepo) TouchAPIKey(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.txManager.update(ctx, func(tx *memoryTx) error {
+		v, ok := tx.get(r.keys.key(id))
+		if !ok {
+			return errors.New("not found id: " + id.String())
+		}
+
+		key := *v.(*domain.APIKey)
+		key.LastUsedAt = at
+		r.keys.put(tx, id, &key)
+		return nil
+	})
+}
+
+func (r *MemoryAPIKeyRepo) DeleteAPIKeyByID(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	var deleted *domain.APIKey
+	err := r.txManager.update(ctx, func(tx *memoryTx) error {
+		var err error
+		deleted, err = r.keys.delete(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+func (r *MemoryAPIKeyRepo) DeleteAPIKeysByUser(ctx context.Context, userID uuid.UUID) (int, error) {
+	deleted := 0
+	err := r.txManager.update(ctx, func(tx *memoryTx) error {
+		for _, v := range tx.list(r.keys.name) {
+			key := v.(*domain.APIKey)
+			if key.UserID != userID {
+				continue
+			}
+			if _, err := r.keys.delete(tx, key.ID); err != nil {
+				return err
+			}
+			deleted++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, nil
+}
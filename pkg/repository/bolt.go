@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	usersBucket        = []byte("users")
+	notionPagesBucket  = []byte("notion_pages")
+	keywordNodesBucket = []byte("keyword_nodes")
+	keywordEdgesBucket = []byte("keyword_edges")
+	sessionsBucket     = []byte("sessions")
+	apiKeysBucket      = []byte("api_keys")
+	authAttemptsBucket = []byte("auth_attempts")
+)
+
+func NewBoltDB(path string) (*bolt.DB, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{
+			usersBucket,
+			notionPagesBucket,
+			keywordNodesBucket,
+			keywordEdgesBucket,
+			sessionsBucket,
+			apiKeysBucket,
+			authAttemptsBucket,
+		} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// boltStore is shared by every bolt repository of one database. bbolt allows a
+// single writer at a time, so a transaction carried by a context is used by
+// all repositories instead of each opening their own.
+type boltStore struct {
+	db *bolt.DB
+}
+
+func newBoltStore(db *bolt.DB) *boltStore {
+	return &boltStore{
+		db: db,
+	}
+}
+
+type boltTx struct {
+	tx *bolt.Tx
+}
+
+func (t *boltTx) commit() error {
+	// bbolt refuses to commit a read-only transaction, which only has to be
+	// released.
+	if !t.tx.Writable() {
+		return t.tx.Rollback()
+	}
+	return t.tx.Commit()
+}
+
+func (t *boltTx) rollback() {
+	if err := t.tx.Rollback(); err != nil && !errors.Is(err, bolt.ErrTxClosed) {
+		log.Printf("fail to rollback transaction: %v", err)
+	}
+}
+
+// BeginTransaction opens a read-only transaction when ctx is marked read-only,
+// which does not wait for nor block the single writer.
+func (s *boltStore) BeginTransaction(ctx context.Context) (context.Context, error) {
+	return beginScope(ctx, s, func(ctx context.Context) (transaction, error) {
+		tx, err := s.db.Begin(!readOnlyFromContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+		return &boltTx{tx: tx}, nil
+	})
+}
+
+func (s *boltStore) Commit(ctx context.Context) error {
+	return commitScope(ctx, s)
+}
+
+func (s *boltStore) Abort(ctx context.Context) {
+	abortScope(ctx, s)
+}
+
+func (s *boltStore) update(ctx context.Context, fn func(tx *bolt.Tx) error) error {
+	tx, ok, err := txFromContext[*boltTx](ctx, s)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return fn(tx.tx)
+	}
+
+	return s.db.Update(fn)
+}
+
+func (s *boltStore) view(ctx context.Context, fn func(tx *bolt.Tx) error) error {
+	tx, ok, err := txFromContext[*boltTx](ctx, s)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return fn(tx.tx)
+	}
+
+	return s.db.View(fn)
+}
+
+func boltPut[T any](tx *bolt.Tx, bucket []byte, id uuid.UUID, v *T) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return tx.Bucket(bucket).Put(id[:], data)
+}
+
+// boltReplace overwrites an existing value and fails with ErrNotFound when
+// there is none.
+func boltReplace[T any](tx *bolt.Tx, bucket []byte, id uuid.UUID, v *T) error {
+	if tx.Bucket(bucket).Get(id[:]) == nil {
+		return fmt.Errorf("%w id: %s", ErrNotFound, id.String())
+	}
+
+	return boltPut(tx, bucket, id, v)
+}
+
+func boltGet[T any](tx *bolt.Tx, bucket []byte, id uuid.UUID) (*T, error) {
+	data := tx.Bucket(bucket).Get(id[:])
+	if data == nil {
+		return nil, errors.New("not found id: " + id.String())
+	}
+
+	v := new(T)
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func boltDelete[T any](tx *bolt.Tx, bucket []byte, id uuid.UUID) (*T, error) {
+	v, err := boltGet[T](tx, bucket, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Bucket(bucket).Delete(id[:]); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func boltList[T any](tx *bolt.Tx, bucket []byte, match func(*T) bool) ([]*T, error) {
+	list := make([]*T, 0)
+	err := tx.Bucket(bucket).ForEach(func(_, data []byte) error {
+		v := new(T)
+		if err := json.Unmarshal(data, v); err != nil {
+			return err
+		}
+		if match(v) {
+			list = append(list, v)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+)
+
+type BoltAPIKeyRepo struct {
+	store *boltStore
+}
+
+func newBoltAPIKeyRepo(store *boltStore) *BoltAPIKeyRepo {
+	return &BoltAPIKeyRepo{
+		store: store,
+	}
+}
+
+func (r *BoltAPIKeyRepo) BeginTransaction(ctx context.Context) (context.Context, error) {
+	return r.store.BeginTransaction(ctx)
+}
+
+func (r *BoltAPIKeyRepo) Commit(ctx context.Context) error {
+	return r.store.Commit(ctx)
+}
+
+func (r *BoltAPIKeyRepo) Abort(ctx context.Context) {
+	r.store.Abort(ctx)
+}
+
+func (r *BoltAPIKeyRepo) CreateAPIKey(ctx context.Context, key *domain.APIKey) (*domain.APIKey, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	key.ID = id
+
+	err = r.store.update(ctx, func(tx *bolt.Tx) error {
+		return boltPut(tx, apiKeysBucket, key.ID, key)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	copied := *key
+	return &copied, nil
+}
+
+func (r *BoltAPIKeyRepo) FindAPIKeyByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	var keys []*domain.APIKey
+	err := r.store.view(ctx, func(tx *bolt.Tx) error {
+		var err error
+		keys, err = boltList(tx, apiKeysBucket, func(k *domain.APIKey) bool {
+			return k.Hash == hash
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w api key", ErrNotFound)
+	}
+
+	return keys[0], nil
+}
+
+func (r *BoltAPIKeyRepo) ListAPIKeysByUser(ctx context.Context, userID uuid.UUID) ([]*domain.APIKey, error) {
+	var keys []*domain.APIKey
+	err := r.store.view(ctx, func(tx *bolt.Tx) error {
+		var err error
+		keys, err = boltList(tx, apiKeysBucket, func(k *domain.APIKey) bool {
+			return k.UserID == userID
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (r *BoltAPIKeyRepo) ListAPIKeys(ctx context.Context) ([]*domain.APIKey, error) {
+	var keys []*domain.APIKey
+	err := r.store.view(ctx, func(tx *bolt.Tx) error {
+		var err error
+		keys, err = boltList(tx, apiKeysBucket, func(*domain.APIKey) bool {
+			return true
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// RestoreAPIKeys stores the keys under their own ids.
+func (r *BoltAPIKeyRepo) RestoreAPIKeys(ctx context.Context, keys ...*domain.APIKey) error {
+	return r.store.update(ctx, func(tx *bolt.Tx) error {
+		for _, key := range keys {
+			if err := boltPut(tx, apiKeysBucket, key.ID, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *BoltAPIKeyRepo) TouchAPIKey(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return r.store.update(ctx, func(tx *bolt.Tx) error {
+		key, err := boltGet[domain.APIKey](tx, apiKeysBucket, id)
+		if err != nil {
+			return err
+		}
+		key.LastUsedAt = at
+		return boltPut(tx, apiKeysBucket, id, key)
+	})
+}
+
+func (r *BoltAPIKeyRepo) DeleteAPIKeyByID(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	var deleted *domain.APIKey
+	err := r.store.update(ctx, func(tx *bolt.Tx) error {
+		var err error
+		deleted, err = boltDelete[domain.APIKey](tx, apiKeysBucket, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+func (r *BoltAPIKeyRepo) DeleteAPIKeysByUser(ctx context.Context, userID uuid.UUID) (int, error) {
+	deleted := 0
+	err := r.store.update(ctx, func(tx *bolt.Tx) error {
+		keys, err := boltList(tx, apiKeysBucket, func(k *domain.APIKey) bool {
+			return k.UserID == userID
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			if _, err := boltDelete[domain.APIKey](tx, apiKeysBucket, k.ID); err != nil {
+				return err
+			}
+		}
+		deleted = len(keys)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, nil
+}
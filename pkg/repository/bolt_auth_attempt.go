@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+)
+
+type BoltAuthAttemptStore struct {
+	store *boltStore
+}
+
+func newBoltAuthAttemptStore(store *boltStore) *BoltAuthAttemptStore {
+	return &BoltAuthAttemptStore{
+		store: store,
+	}
+}
+
+func (s *BoltAuthAttemptStore) Put(ctx context.Context, attempt *domain.AuthAttempt) error {
+	data, err := json.Marshal(attempt)
+	if err != nil {
+		return err
+	}
+
+	return s.store.update(ctx, func(tx *bolt.Tx) error {
+		return tx.Bucket(authAttemptsBucket).Put([]byte(attempt.ID), data)
+	})
+}
+
+func (s *BoltAuthAttemptStore) Take(ctx context.Context, id string) (*domain.AuthAttempt, bool, error) {
+	var attempt *domain.AuthAttempt
+	err := s.store.update(ctx, func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(authAttemptsBucket)
+		data := bucket.Get([]byte(id))
+		if data == nil {
+			return nil
+		}
+
+		attempt = &domain.AuthAttempt{}
+		if err := json.Unmarshal(data, attempt); err != nil {
+			return err
+		}
+		return bucket.Delete([]byte(id))
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return attempt, attempt != nil, nil
+}
+
+func (s *BoltAuthAttemptStore) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	deleted := 0
+	err := s.store.update(ctx, func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(authAttemptsBucket)
+
+		ids := make([][]byte, 0)
+		err := bucket.ForEach(func(id, data []byte) error {
+			attempt := &domain.AuthAttempt{}
+			if err := json.Unmarshal(data, attempt); err != nil {
+				return err
+			}
+			if attempt.ExpiresAt.Before(before) {
+				ids = append(ids, append([]byte(nil), id...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// bbolt does not allow deleting keys while iterating over them.
+		for _, id := range ids {
+			if err := bucket.Delete(id); err != nil {
+				return err
+			}
+		}
+		deleted = len(ids)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, nil
+}
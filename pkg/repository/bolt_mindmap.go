@@ -0,0 +1,333 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+)
+
+type BoltMindMapRepo struct {
+	store *boltStore
+}
+
+func newBoltMindMapRepo(store *boltStore) *BoltMindMapRepo {
+	return &BoltMindMapRepo{
+		store: store,
+	}
+}
+
+func (r *BoltMindMapRepo) BeginTransaction(ctx context.Context) (context.Context, error) {
+	return r.store.BeginTransaction(ctx)
+}
+
+func (r *BoltMindMapRepo) Commit(ctx context.Context) error {
+	return r.store.Commit(ctx)
+}
+
+func (r *BoltMindMapRepo) Abort(ctx context.Context) {
+	r.store.Abort(ctx)
+}
+
+func (r *BoltMindMapRepo) CreateKeywordNode(
+	ctx context.Context,
+	node *domain.KeywordNode,
+) (*domain.KeywordNode, error) {
+	created, err := r.CreateBulkKeywordNodes(ctx, node)
+	if err != nil {
+		return nil, err
+	}
+
+	return created[0], nil
+}
+
+func (r *BoltMindMapRepo) CreateBulkKeywordNodes(
+	ctx context.Context,
+	bulks ...*domain.KeywordNode,
+) ([]*domain.KeywordNode, error) {
+	nodeCopies := make([]*domain.KeywordNode, 0, len(bulks))
+	err := r.store.update(ctx, func(tx *bolt.Tx) error {
+		for _, node := range bulks {
+			id, err := uuid.NewRandom()
+			if err != nil {
+				return err
+			}
+			node.ID = id
+			if err := boltPut(tx, keywordNodesBucket, node.ID, node); err != nil {
+				return err
+			}
+			copied := *node
+			nodeCopies = append(nodeCopies, &copied)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return nodeCopies, nil
+}
+
+func (r *BoltMindMapRepo) FindKeywordNodeByID(
+	ctx context.Context,
+	id uuid.UUID,
+) (*domain.KeywordNode, error) {
+	var node *domain.KeywordNode
+	err := r.store.view(ctx, func(tx *bolt.Tx) error {
+		var err error
+		node, err = boltGet[domain.KeywordNode](tx, keywordNodesBucket, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return node, nil
+}
+
+func (r *BoltMindMapRepo) ListKeywordNodeByUser(
+	ctx context.Context,
+	userID uuid.UUID,
+) ([]*domain.KeywordNode, error) {
+	return r.listKeywordNodes(ctx, func(n *domain.KeywordNode) bool {
+		return n.UserID == userID
+	})
+}
+
+func (r *BoltMindMapRepo) ListKeywordNodeByNotionPage(
+	ctx context.Context,
+	notionID uuid.UUID,
+) ([]*domain.KeywordNode, error) {
+	return r.listKeywordNodes(ctx, func(n *domain.KeywordNode) bool {
+		return n.NotionPageID == notionID
+	})
+}
+
+func (r *BoltMindMapRepo) ListKeywordNodes(ctx context.Context) ([]*domain.KeywordNode, error) {
+	return r.listKeywordNodes(ctx, func(*domain.KeywordNode) bool {
+		return true
+	})
+}
+
+// RestoreBulkKeywordNodes stores the nodes under their own ids.
+func (r *BoltMindMapRepo) RestoreBulkKeywordNodes(
+	ctx context.Context,
+	bulks ...*domain.KeywordNode,
+) error {
+	return r.store.update(ctx, func(tx *bolt.Tx) error {
+		for _, node := range bulks {
+			if err := boltPut(tx, keywordNodesBucket, node.ID, node); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// MarkKeywordNodesStale flags the nodes extracted from the given notion pages
+// and returns how many were not flagged yet.
+func (r *BoltMindMapRepo) MarkKeywordNodesStale(ctx context.Context, notionIDs []uuid.UUID) (int, error) {
+	pages := make(map[uuid.UUID]bool, len(notionIDs))
+	for _, id := range notionIDs {
+		pages[id] = true
+	}
+
+	marked := 0
+	err := r.store.update(ctx, func(tx *bolt.Tx) error {
+		nodes, err := boltList(tx, keywordNodesBucket, func(n *domain.KeywordNode) bool {
+			return !n.Stale && pages[n.NotionPageID]
+		})
+		if err != nil {
+			return err
+		}
+		for _, node := range nodes {
+			node.Stale = true
+			if err := boltPut(tx, keywordNodesBucket, node.ID, node); err != nil {
+				return err
+			}
+		}
+		marked = len(nodes)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return marked, nil
+}
+
+func (r *BoltMindMapRepo) DeleteKeywordNodeByID(
+	ctx context.Context,
+	id uuid.UUID,
+) (*domain.KeywordNode, error) {
+	deleted, err := r.DeleteBulkKeywordNodes(ctx, []uuid.UUID{id})
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted[0], nil
+}
+
+func (r *BoltMindMapRepo) DeleteBulkKeywordNodes(
+	ctx context.Context,
+	ids []uuid.UUID,
+) ([]*domain.KeywordNode, error) {
+	deleted := make([]*domain.KeywordNode, 0, len(ids))
+	err := r.store.update(ctx, func(tx *bolt.Tx) error {
+		for _, id := range ids {
+			node, err := boltDelete[domain.KeywordNode](tx, keywordNodesBucket, id)
+			if err != nil {
+				return err
+			}
+			deleted = append(deleted, node)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+func (r *BoltMindMapRepo) CreateKeywordEdge(
+	ctx context.Context,
+	edge *domain.KeywordEdge,
+) (*domain.KeywordEdge, error) {
+	created, err := r.CreateBulkKeywordEdges(ctx, edge)
+	if err != nil {
+		return nil, err
+	}
+
+	return created[0], nil
+}
+
+func (r *BoltMindMapRepo) CreateBulkKeywordEdges(
+	ctx context.Context,
+	bulks ...*domain.KeywordEdge,
+) ([]*domain.KeywordEdge, error) {
+	edgeCopies := make([]*domain.KeywordEdge, 0, len(bulks))
+	err := r.store.update(ctx, func(tx *bolt.Tx) error {
+		for _, edge := range bulks {
+			id, err := uuid.NewRandom()
+			if err != nil {
+				return err
+			}
+			edge.ID = id
+			if err := boltPut(tx, keywordEdgesBucket, edge.ID, edge); err != nil {
+				return err
+			}
+			copied := *edge
+			edgeCopies = append(edgeCopies, &copied)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return edgeCopies, nil
+}
+
+func (r *BoltMindMapRepo) ListKeywordEdgeByUser(
+	ctx context.Context,
+	userID uuid.UUID,
+) ([]*domain.KeywordEdge, error) {
+	var edges []*domain.KeywordEdge
+	err := r.store.view(ctx, func(tx *bolt.Tx) error {
+		var err error
+		edges, err = boltList(tx, keywordEdgesBucket, func(e *domain.KeywordEdge) bool {
+			return e.UserID == userID
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return edges, nil
+}
+
+func (r *BoltMindMapRepo) ListKeywordEdges(ctx context.Context) ([]*domain.KeywordEdge, error) {
+	var edges []*domain.KeywordEdge
+	err := r.store.view(ctx, func(tx *bolt.Tx) error {
+		var err error
+		edges, err = boltList(tx, keywordEdgesBucket, func(*domain.KeywordEdge) bool {
+			return true
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return edges, nil
+}
+
+// RestoreBulkKeywordEdges stores the edges under their own ids.
+func (r *BoltMindMapRepo) RestoreBulkKeywordEdges(
+	ctx context.Context,
+	bulks ...*domain.KeywordEdge,
+) error {
+	return r.store.update(ctx, func(tx *bolt.Tx) error {
+		for _, edge := range bulks {
+			if err := boltPut(tx, keywordEdgesBucket, edge.ID, edge); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *BoltMindMapRepo) DeleteKeywordEdgeByID(
+	ctx context.Context,
+	id uuid.UUID,
+) (*domain.KeywordEdge, error) {
+	deleted, err := r.DeleteBulkKeywordEdges(ctx, []uuid.UUID{id})
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted[0], nil
+}
+
+func (r *BoltMindMapRepo) DeleteBulkKeywordEdges(
+	ctx context.Context,
+	ids []uuid.UUID,
+) ([]*domain.KeywordEdge, error) {
+	deleted := make([]*domain.KeywordEdge, 0, len(ids))
+	err := r.store.update(ctx, func(tx *bolt.Tx) error {
+		for _, id := range ids {
+			edge, err := boltDelete[domain.KeywordEdge](tx, keywordEdgesBucket, id)
+			if err != nil {
+				return err
+			}
+			deleted = append(deleted, edge)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+func (r *BoltMindMapRepo) listKeywordNodes(
+	ctx context.Context,
+	match func(*domain.KeywordNode) bool,
+) ([]*domain.KeywordNode, error) {
+	var nodes []*domain.KeywordNode
+	err := r.store.view(ctx, func(tx *bolt.Tx) error {
+		var err error
+		nodes, err = boltList(tx, keywordNodesBucket, match)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
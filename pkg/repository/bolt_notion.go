@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+)
+
+type BoltNotionPageRepo struct {
+	store *boltStore
+}
+
+func newBoltNotionPageRepo(store *boltStore) *BoltNotionPageRepo {
+	return &BoltNotionPageRepo{
+		store: store,
+	}
+}
+
+func (r *BoltNotionPageRepo) BeginTransaction(ctx context.Context) (context.Context, error) {
+	return r.store.BeginTransaction(ctx)
+}
+
+func (r *BoltNotionPageRepo) Commit(ctx context.Context) error {
+	return r.store.Commit(ctx)
+}
+
+func (r *BoltNotionPageRepo) Abort(ctx context.Context) {
+	r.store.Abort(ctx)
+}
+
+func (r *BoltNotionPageRepo) CreateNotionPage(
+	ctx context.Context,
+	page *domain.NotionPage,
+) (*domain.NotionPage, error) {
+	created, err := r.CreateNotionPages(ctx, []*domain.NotionPage{page})
+	if err != nil {
+		return nil, err
+	}
+
+	return created[0], nil
+}
+
+func (r *BoltNotionPageRepo) CreateNotionPages(
+	ctx context.Context,
+	pages []*domain.NotionPage,
+) ([]*domain.NotionPage, error) {
+	createdPages := make([]*domain.NotionPage, 0, len(pages))
+	err := r.store.update(ctx, func(tx *bolt.Tx) error {
+		for _, page := range pages {
+			if page == nil {
+				continue
+			}
+			id, err := uuid.NewRandom()
+			if err != nil {
+				return err
+			}
+			page.ID = id
+			if err := boltPut(tx, notionPagesBucket, page.ID, page); err != nil {
+				return err
+			}
+			copied := *page
+			createdPages = append(createdPages, &copied)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return createdPages, nil
+}
+
+func (r *BoltNotionPageRepo) FindAllNotionPagesByUser(
+	ctx context.Context,
+	userID uuid.UUID,
+) ([]*domain.NotionPage, error) {
+	var pages []*domain.NotionPage
+	err := r.store.view(ctx, func(tx *bolt.Tx) error {
+		var err error
+		pages, err = boltList(tx, notionPagesBucket, func(p *domain.NotionPage) bool {
+			return p.UserID == userID
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pages, nil
+}
+
+func (r *BoltNotionPageRepo) FindNotionPageByID(
+	ctx context.Context,
+	id uuid.UUID,
+) (*domain.NotionPage, error) {
+	var page *domain.NotionPage
+	err := r.store.view(ctx, func(tx *bolt.Tx) error {
+		var err error
+		page, err = boltGet[domain.NotionPage](tx, notionPagesBucket, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+func (r *BoltNotionPageRepo) ListNotionPages(ctx context.Context) ([]*domain.NotionPage, error) {
+	var pages []*domain.NotionPage
+	err := r.store.view(ctx, func(tx *bolt.Tx) error {
+		var err error
+		pages, err = boltList(tx, notionPagesBucket, func(*domain.NotionPage) bool {
+			return true
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pages, nil
+}
+
+func (r *BoltNotionPageRepo) UpdateNotionPage(
+	ctx context.Context,
+	page *domain.NotionPage,
+) (*domain.NotionPage, error) {
+	err := r.store.update(ctx, func(tx *bolt.Tx) error {
+		return boltReplace(tx, notionPagesBucket, page.ID, page)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	copied := *page
+	return &copied, nil
+}
+
+// RestoreNotionPages stores the pages under their own ids.
+func (r *BoltNotionPageRepo) RestoreNotionPages(ctx context.Context, pages ...*domain.NotionPage) error {
+	return r.store.update(ctx, func(tx *bolt.Tx) error {
+		for _, page := range pages {
+			if err := boltPut(tx, notionPagesBucket, page.ID, page); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *BoltNotionPageRepo) DeleteNotionPageByID(
+	ctx context.Context,
+	id uuid.UUID,
+) (*domain.NotionPage, error) {
+	var deleted *domain.NotionPage
+	err := r.store.update(ctx, func(tx *bolt.Tx) error {
+		var err error
+		deleted, err = boltDelete[domain.NotionPage](tx, notionPagesBucket, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+)
+
+type BoltSessionStore struct {
+	store *boltStore
+}
+
+func newBoltSessionStore(store *boltStore) *BoltSessionStore {
+	return &BoltSessionStore{
+		store: store,
+	}
+}
+
+// boltSession keeps the ID which domain.Session leaves out of its JSON.
+type boltSession struct {
+	ID string `json:"id"`
+	*domain.Session
+}
+
+func (s *BoltSessionStore) Get(ctx context.Context, id string) (*domain.Session, bool, error) {
+	var session *domain.Session
+	err := s.store.view(ctx, func(tx *bolt.Tx) error {
+		var err error
+		session, err = getBoltSession(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return session, session != nil, nil
+}
+
+func (s *BoltSessionStore) Set(ctx context.Context, session *domain.Session) error {
+	return s.store.update(ctx, func(tx *bolt.Tx) error {
+		return putBoltSession(tx, session)
+	})
+}
+
+func (s *BoltSessionStore) Delete(ctx context.Context, id string) error {
+	return s.store.update(ctx, func(tx *bolt.Tx) error {
+		return tx.Bucket(sessionsBucket).Delete([]byte(id))
+	})
+}
+
+func (s *BoltSessionStore) Touch(ctx context.Context, id string, at time.Time) error {
+	return s.store.update(ctx, func(tx *bolt.Tx) error {
+		session, err := getBoltSession(tx, id)
+		if err != nil || session == nil {
+			return err
+		}
+		session.LastSeenAt = at
+		return putBoltSession(tx, session)
+	})
+}
+
+func (s *BoltSessionStore) ListByUser(
+	ctx context.Context,
+	userID uuid.UUID,
+) ([]*domain.Session, error) {
+	sessions := make([]*domain.Session, 0)
+	err := s.store.view(ctx, func(tx *bolt.Tx) error {
+		return forEachBoltSession(tx, func(session *domain.Session) error {
+			if session.UserID == userID {
+				sessions = append(sessions, session)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (s *BoltSessionStore) DeleteByUser(ctx context.Context, userID uuid.UUID) (int, error) {
+	return s.deleteMatching(ctx, func(session *domain.Session) bool {
+		return session.UserID == userID
+	})
+}
+
+func (s *BoltSessionStore) DeleteExpired(
+	ctx context.Context,
+	createdBefore, idleBefore time.Time,
+) (int, error) {
+	return s.deleteMatching(ctx, func(session *domain.Session) bool {
+		return session.CreatedAt.Before(createdBefore) || session.LastSeenAt.Before(idleBefore)
+	})
+}
+
+func (s *BoltSessionStore) deleteMatching(
+	ctx context.Context,
+	match func(*domain.Session) bool,
+) (int, error) {
+	deleted := 0
+	err := s.store.update(ctx, func(tx *bolt.Tx) error {
+		ids := make([]string, 0)
+		err := forEachBoltSession(tx, func(session *domain.Session) error {
+			if match(session) {
+				ids = append(ids, session.ID)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// bbolt does not allow deleting keys while iterating over them.
+		for _, id := range ids {
+			if err := tx.Bucket(sessionsBucket).Delete([]byte(id)); err != nil {
+				return err
+			}
+		}
+		deleted = len(ids)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted, nil
+}
+
+func getBoltSession(tx *bolt.Tx, id string) (*domain.Session, error) {
+	data := tx.Bucket(sessionsBucket).Get([]byte(id))
+	if data == nil {
+		return nil, nil
+	}
+
+	return decodeBoltSession(data)
+}
+
+func putBoltSession(tx *bolt.Tx, session *domain.Session) error {
+	data, err := json.Marshal(&boltSession{ID: session.ID, Session: session})
+	if err != nil {
+		return err
+	}
+
+	return tx.Bucket(sessionsBucket).Put([]byte(session.ID), data)
+}
+
+func forEachBoltSession(tx *bolt.Tx, fn func(*domain.Session) error) error {
+	return tx.Bucket(sessionsBucket).ForEach(func(_, data []byte) error {
+		session, err := decodeBoltSession(data)
+		if err != nil {
+			return err
+		}
+		return fn(session)
+	})
+}
+
+func decodeBoltSession(data []byte) (*domain.Session, error) {
+	stored := &boltSession{Session: &domain.Session{}}
+	if err := json.Unmarshal(data, stored); err != nil {
+		return nil, err
+	}
+	stored.Session.ID = stored.ID
+
+	return stored.Session, nil
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+)
+
+type BoltUserRepo struct {
+	store *boltStore
+}
+
+func newBoltUserRepo(store *boltStore) *BoltUserRepo {
+	return &BoltUserRepo{
+		store: store,
+	}
+}
+
+func (r *BoltUserRepo) BeginTransaction(ctx context.Context) (context.Context, error) {
+	return r.store.BeginTransaction(ctx)
+}
+
+func (r *BoltUserRepo) Commit(ctx context.Context) error {
+	return r.store.Commit(ctx)
+}
+
+func (r *BoltUserRepo) Abort(ctx context.Context) {
+	r.store.Abort(ctx)
+}
+
+func (r *BoltUserRepo) CreateUser(ctx context.Context, user *domain.User) (*domain.User, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	user.ID = id
+
+	err = r.store.update(ctx, func(tx *bolt.Tx) error {
+		return boltPut(tx, usersBucket, user.ID, user.Record())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	copied := *user
+	return &copied, nil
+}
+
+func (r *BoltUserRepo) FindUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	var user *domain.User
+	err := r.store.view(ctx, func(tx *bolt.Tx) error {
+		var err error
+		user, err = getBoltUser(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *BoltUserRepo) FindUserByNotionUserID(
+	ctx context.Context,
+	notionUserID uuid.UUID,
+) (*domain.User, error) {
+	var users []*domain.User
+	err := r.store.view(ctx, func(tx *bolt.Tx) error {
+		var err error
+		users, err = listBoltUsers(tx, func(u *domain.User) bool {
+			return u.NotionUserID == notionUserID
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("%w notion user id: %s", ErrNotFound, notionUserID.String())
+	}
+
+	return users[0], nil
+}
+
+func (r *BoltUserRepo) FindUserByNotionBotID(ctx context.Context, botID string) (*domain.User, error) {
+	var users []*domain.User
+	err := r.store.view(ctx, func(tx *bolt.Tx) error {
+		var err error
+		users, err = listBoltUsers(tx, func(u *domain.User) bool {
+			return u.NotionBotID == botID
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("%w notion bot id: %s", ErrNotFound, botID)
+	}
+
+	return users[0], nil
+}
+
+func (r *BoltUserRepo) ListUsers(ctx context.Context) ([]*domain.User, error) {
+	var users []*domain.User
+	err := r.store.view(ctx, func(tx *bolt.Tx) error {
+		var err error
+		users, err = listBoltUsers(tx, func(*domain.User) bool {
+			return true
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (r *BoltUserRepo) UpdateUser(ctx context.Context, user *domain.User) (*domain.User, error) {
+	err := r.store.update(ctx, func(tx *bolt.Tx) error {
+		return boltReplace(tx, usersBucket, user.ID, user.Record())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	copied := *user
+	return &copied, nil
+}
+
+// RestoreUsers stores the users under their own ids.
+func (r *BoltUserRepo) RestoreUsers(ctx context.Context, users ...*domain.User) error {
+	return r.store.update(ctx, func(tx *bolt.Tx) error {
+		for _, user := range users {
+			if err := boltPut(tx, usersBucket, user.ID, user.Record()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *BoltUserRepo) DeleteUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	var deleted *domain.User
+	err := r.store.update(ctx, func(tx *bolt.Tx) error {
+		var err error
+		deleted, err = deleteBoltUser(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+// The users are stored as domain.UserRecord, since domain.User leaves the
+// notion tokens out of its JSON.
+
+func getBoltUser(tx *bolt.Tx, id uuid.UUID) (*domain.User, error) {
+	record, err := boltGet[domain.UserRecord](tx, usersBucket, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return record.ToUser(), nil
+}
+
+func listBoltUsers(tx *bolt.Tx, match func(*domain.User) bool) ([]*domain.User, error) {
+	records, err := boltList(tx, usersBucket, func(*domain.UserRecord) bool {
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]*domain.User, 0)
+	for _, record := range records {
+		if user := record.ToUser(); match(user) {
+			users = append(users, user)
+		}
+	}
+
+	return users, nil
+}
+
+func deleteBoltUser(tx *bolt.Tx, id uuid.UUID) (*domain.User, error) {
+	record, err := boltDelete[domain.UserRecord](tx, usersBucket, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return record.ToUser(), nil
+}
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/config"
+)
+
+// encryptedPrefix marks an encrypted value. Values without it were stored
+// before encryption was configured and are read as they are.
+const encryptedPrefix = "enc:v1:"
+
+var ErrNoEncryptionKey = errors.New("no token encryption key is configured")
+
+// TokenCipher encrypts secrets with envelope encryption. Every value is sealed
+// with its own random data key, which is stored next to it sealed by the key
+// encryption key, so rotating the key encryption key never needs the old
+// plaintext outside of this type.
+//
+// An encrypted value looks like "enc:v1:<key id>:<sealed data key>:<sealed value>".
+type TokenCipher struct {
+	current *cipherKey
+	keys    map[string]*cipherKey
+}
+
+type cipherKey struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// NewTokenCipher returns a cipher for the configured keys. Without a key it
+// stores new values in plaintext, but still refuses to read encrypted ones.
+func NewTokenCipher(cfg *config.EncryptionConfig) (*TokenCipher, error) {
+	c := &TokenCipher{
+		keys: make(map[string]*cipherKey),
+	}
+	if cfg == nil {
+		return c, nil
+	}
+
+	if cfg.Key != "" {
+		key, err := parseCipherKey(cfg.Key)
+		if err != nil {
+			return nil, err
+		}
+		c.current = key
+		c.keys[key.id] = key
+	}
+
+	for _, s := range cfg.OldKeys {
+		if s == "" {
+			continue
+		}
+		key, err := parseCipherKey(s)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := c.keys[key.id]; ok {
+			return nil, fmt.Errorf("duplicated token encryption key id: %s", key.id)
+		}
+		c.keys[key.id] = key
+	}
+
+	return c, nil
+}
+
+func parseCipherKey(s string) (*cipherKey, error) {
+	id, encoded, ok := strings.Cut(s, ":")
+	if !ok || id == "" {
+		return nil, errors.New("token encryption key must look like <id>:<base64 key>")
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("token encryption key %s: %w", id, err)
+	}
+	if len(raw) != 32 {
+		return nil, fmt.Errorf("token encryption key %s must be 32 bytes, got %d", id, len(raw))
+	}
+
+	aead, err := newAEAD(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cipherKey{id: id, aead: aead}, nil
+}
+
+// Enabled reports whether new values are encrypted.
+func (c *TokenCipher) Enabled() bool {
+	return c.current != nil
+}
+
+// Encrypt seals plaintext under the current key. Empty values stay empty.
+func (c *TokenCipher) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" || c.current == nil {
+		return plaintext, nil
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	value, err := sealWith(dataAEAD, []byte(plaintext), nil)
+	if err != nil {
+		return "", err
+	}
+	// The key id is authenticated so a data key cannot be moved under
+	// another key encryption key.
+	wrapped, err := sealWith(c.current.aead, dataKey, []byte(c.current.id))
+	if err != nil {
+		return "", err
+	}
+
+	return encryptedPrefix + c.current.id + ":" +
+		base64.RawURLEncoding.EncodeToString(wrapped) + ":" +
+		base64.RawURLEncoding.EncodeToString(value), nil
+}
+
+// Decrypt opens a value written by Encrypt with whichever configured key
+// sealed it. Plaintext values are returned unchanged.
+func (c *TokenCipher) Decrypt(stored string) (string, error) {
+	rest, ok := strings.CutPrefix(stored, encryptedPrefix)
+	if !ok {
+		return stored, nil
+	}
+
+	parts := strings.Split(rest, ":")
+	if len(parts) != 3 {
+		return "", errors.New("malformed encrypted token")
+	}
+	if len(c.keys) == 0 {
+		return "", ErrNoEncryptionKey
+	}
+	key, ok := c.keys[parts[0]]
+	if !ok {
+		return "", fmt.Errorf("token is encrypted with unknown key: %s", parts[0])
+	}
+
+	wrapped, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", err
+	}
+	value, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", err
+	}
+
+	dataKey, err := openWith(key.aead, wrapped, []byte(key.id))
+	if err != nil {
+		return "", fmt.Errorf("fail to unwrap token data key: %w", err)
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := openWith(dataAEAD, value, nil)
+	if err != nil {
+		return "", fmt.Errorf("fail to decrypt token: %w", err)
+	}
+
+	return string(plaintext), nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func sealWith(aead cipher.AEAD, plaintext, additional []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additional), nil
+}
+
+func openWith(aead cipher.AEAD, sealed, additional []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("sealed value is too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	return aead.Open(nil, nonce, ciphertext, additional)
+}
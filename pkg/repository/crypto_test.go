@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/config"
+)
+
+func newTestCipherKey(t *testing.T, id string) string {
+	t.Helper()
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		t.Fatal(err)
+	}
+
+	return id + ":" + base64.StdEncoding.EncodeToString(raw)
+}
+
+func newTestCipher(t *testing.T, key string, oldKeys ...string) *TokenCipher {
+	t.Helper()
+
+	c, err := NewTokenCipher(&config.EncryptionConfig{Key: key, OldKeys: oldKeys})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func TestTokenCipherRoundTrip(t *testing.T) {
+	c := newTestCipher(t, newTestCipherKey(t, "key1"))
+
+	for _, plaintext := range []string{"secret_notion_token", "ntn_" + strings.Repeat("x", 512), ""} {
+		sealed, err := c.Encrypt(plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if plaintext != "" && (sealed == plaintext || !strings.HasPrefix(sealed, encryptedPrefix+"key1:")) {
+			t.Fatalf("Encrypt(%q) = %q, want a value sealed under key1", plaintext, sealed)
+		}
+
+		opened, err := c.Decrypt(sealed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if opened != plaintext {
+			t.Fatalf("Decrypt(Encrypt(%q)) = %q", plaintext, opened)
+		}
+	}
+
+	first, _ := c.Encrypt("secret_notion_token")
+	second, _ := c.Encrypt("secret_notion_token")
+	if first == second {
+		t.Fatal("the same plaintext was sealed to the same value twice")
+	}
+}
+
+func TestTokenCipherPlaintextValues(t *testing.T) {
+	c := newTestCipher(t, "")
+	if c.Enabled() {
+		t.Fatal("cipher without a key is enabled")
+	}
+
+	stored, err := c.Encrypt("secret_notion_token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored != "secret_notion_token" {
+		t.Fatalf("Encrypt without a key = %q, want the plaintext", stored)
+	}
+
+	sealed, err := newTestCipher(t, newTestCipherKey(t, "key1")).Encrypt("secret_notion_token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Decrypt(sealed); !errors.Is(err, ErrNoEncryptionKey) {
+		t.Fatalf("Decrypt without a key = %v, want %v", err, ErrNoEncryptionKey)
+	}
+}
+
+func TestTokenCipherWrongKey(t *testing.T) {
+	sealed, err := newTestCipher(t, newTestCipherKey(t, "key1")).Encrypt("secret_notion_token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Same id, different key material.
+	if _, err := newTestCipher(t, newTestCipherKey(t, "key1")).Decrypt(sealed); err == nil {
+		t.Fatal("value was opened with a different key of the same id")
+	}
+	if _, err := newTestCipher(t, newTestCipherKey(t, "key2")).Decrypt(sealed); err == nil {
+		t.Fatal("value was opened without the key it was sealed with")
+	}
+
+	i := strings.LastIndex(sealed, ":")
+	value, err := base64.RawURLEncoding.DecodeString(sealed[i+1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	value[len(value)-1] ^= 1
+	tampered := sealed[:i+1] + base64.RawURLEncoding.EncodeToString(value)
+	if _, err := newTestCipher(t, newTestCipherKey(t, "key1")).Decrypt(tampered); err == nil {
+		t.Fatal("tampered value was opened")
+	}
+}
+
+func TestTokenCipherKeyRotation(t *testing.T) {
+	oldKey := newTestCipherKey(t, "key1")
+	newKey := newTestCipherKey(t, "key2")
+
+	sealed, err := newTestCipher(t, oldKey).Encrypt("secret_notion_token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated := newTestCipher(t, newKey, oldKey)
+	opened, err := rotated.Decrypt(sealed)
+	if err != nil {
+		t.Fatalf("old value is unreadable after the rotation: %v", err)
+	}
+	if opened != "secret_notion_token" {
+		t.Fatalf("Decrypt = %q, want %q", opened, "secret_notion_token")
+	}
+
+	resealed, err := rotated.Encrypt(opened)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(resealed, encryptedPrefix+"key2:") {
+		t.Fatalf("value is not sealed under the new key: %q", resealed)
+	}
+	if _, err := newTestCipher(t, newKey).Decrypt(resealed); err != nil {
+		t.Fatalf("resealed value needs the old key: %v", err)
+	}
+}
+
+func TestNewTokenCipherRejectsInvalidKeys(t *testing.T) {
+	key := newTestCipherKey(t, "key1")
+
+	for name, cfg := range map[string]*config.EncryptionConfig{
+		"missing id":   {Key: strings.TrimPrefix(key, "key1:")},
+		"short key":    {Key: "key1:" + base64.StdEncoding.EncodeToString([]byte("short"))},
+		"invalid b64":  {Key: "key1:not base64"},
+		"duplicate id": {Key: key, OldKeys: []string{newTestCipherKey(t, "key1")}},
+	} {
+		if _, err := NewTokenCipher(cfg); err == nil {
+			t.Errorf("%s: NewTokenCipher succeeded", name)
+		}
+	}
+}
+
+func TestNewStorageCipherRequiresKey(t *testing.T) {
+	if _, err := newStorageCipher(&config.EncryptionConfig{}); err == nil {
+		t.Fatal("storage cipher without a key was accepted")
+	}
+	if _, err := newStorageCipher(&config.EncryptionConfig{AllowPlaintextTokens: true}); err != nil {
+		t.Fatalf("plaintext tokens were refused despite the opt-out: %v", err)
+	}
+	if _, err := newStorageCipher(&config.EncryptionConfig{Key: newTestCipherKey(t, "key1")}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+)
+
+// encryptedUserRepo keeps the notion tokens of the users encrypted in the
+// wrapped repository. Callers only ever see plaintext tokens.
+type encryptedUserRepo struct {
+	UserRepository
+	cipher *TokenCipher
+}
+
+func newEncryptedUserRepo(repo UserRepository, cipher *TokenCipher) *encryptedUserRepo {
+	return &encryptedUserRepo{
+		UserRepository: repo,
+		cipher:         cipher,
+	}
+}
+
+var _ UserRepository = (*encryptedUserRepo)(nil)
+
+func (r *encryptedUserRepo) CreateUser(ctx context.Context, user *domain.User) (*domain.User, error) {
+	sealed, err := r.seal(user)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := r.UserRepository.CreateUser(ctx, sealed)
+	if err != nil {
+		return nil, err
+	}
+	user.ID = created.ID
+
+	return r.open(created, nil)
+}
+
+func (r *encryptedUserRepo) FindUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	return r.open(r.UserRepository.FindUserByID(ctx, id))
+}
+
+func (r *encryptedUserRepo) FindUserByNotionUserID(
+	ctx context.Context,
+	notionUserID uuid.UUID,
+) (*domain.User, error) {
+	return r.open(r.UserRepository.FindUserByNotionUserID(ctx, notionUserID))
+}
+
+func (r *encryptedUserRepo) FindUserByNotionBotID(ctx context.Context, botID string) (*domain.User, error) {
+	return r.open(r.UserRepository.FindUserByNotionBotID(ctx, botID))
+}
+
+func (r *encryptedUserRepo) ListUsers(ctx context.Context) ([]*domain.User, error) {
+	users, err := r.UserRepository.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, u := range users {
+		if users[i], err = r.open(u, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return users, nil
+}
+
+func (r *encryptedUserRepo) UpdateUser(ctx context.Context, user *domain.User) (*domain.User, error) {
+	sealed, err := r.seal(user)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.open(r.UserRepository.UpdateUser(ctx, sealed))
+}
+
+func (r *encryptedUserRepo) RestoreUsers(ctx context.Context, users ...*domain.User) error {
+	sealed := make([]*domain.User, 0, len(users))
+	for _, u := range users {
+		s, err := r.seal(u)
+		if err != nil {
+			return err
+		}
+		sealed = append(sealed, s)
+	}
+
+	return r.UserRepository.RestoreUsers(ctx, sealed...)
+}
+
+func (r *encryptedUserRepo) DeleteUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	return r.open(r.UserRepository.DeleteUserByID(ctx, id))
+}
+
+func (r *encryptedUserRepo) seal(user *domain.User) (*domain.User, error) {
+	sealed := *user
+
+	var err error
+	if sealed.AccessToken, err = r.cipher.Encrypt(user.AccessToken); err != nil {
+		return nil, err
+	}
+	if sealed.RefreshToken, err = r.cipher.Encrypt(user.RefreshToken); err != nil {
+		return nil, err
+	}
+
+	return &sealed, nil
+}
+
+// open takes the result of the wrapped repository as it is, so lookups can be
+// passed straight through.
+func (r *encryptedUserRepo) open(user *domain.User, err error) (*domain.User, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	if user.AccessToken, err = r.cipher.Decrypt(user.AccessToken); err != nil {
+		return nil, err
+	}
+	if user.RefreshToken, err = r.cipher.Decrypt(user.RefreshToken); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// encryptedSessionStore does the same for the notion token copied into the
+// sessions of the persistent session stores.
+type encryptedSessionStore struct {
+	SessionStore
+	cipher *TokenCipher
+}
+
+func newEncryptedSessionStore(store SessionStore, cipher *TokenCipher) *encryptedSessionStore {
+	return &encryptedSessionStore{
+		SessionStore: store,
+		cipher:       cipher,
+	}
+}
+
+var _ SessionStore = (*encryptedSessionStore)(nil)
+
+func (s *encryptedSessionStore) Get(ctx context.Context, id string) (*domain.Session, bool, error) {
+	session, ok, err := s.SessionStore.Get(ctx, id)
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+
+	if err := s.open(session); err != nil {
+		return nil, false, err
+	}
+
+	return session, true, nil
+}
+
+func (s *encryptedSessionStore) Set(ctx context.Context, session *domain.Session) error {
+	sealed := *session
+	if session.Token != nil {
+		token := *session.Token
+
+		var err error
+		if token.AccessToken, err = s.cipher.Encrypt(session.Token.AccessToken); err != nil {
+			return err
+		}
+		if token.RefreshToken, err = s.cipher.Encrypt(session.Token.RefreshToken); err != nil {
+			return err
+		}
+		sealed.Token = &token
+	}
+
+	return s.SessionStore.Set(ctx, &sealed)
+}
+
+func (s *encryptedSessionStore) ListByUser(ctx context.Context, userID uuid.UUID) ([]*domain.Session, error) {
+	sessions, err := s.SessionStore.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, session := range sessions {
+		if err := s.open(session); err != nil {
+			return nil, err
+		}
+	}
+
+	return sessions, nil
+}
+
+func (s *encryptedSessionStore) open(session *domain.Session) error {
+	if session.Token == nil {
+		return nil
+	}
+
+	var err error
+	if session.Token.AccessToken, err = s.cipher.Decrypt(session.Token.AccessToken); err != nil {
+		return err
+	}
+	if session.Token.RefreshToken, err = s.cipher.Decrypt(session.Token.RefreshToken); err != nil {
+		return err
+	}
+
+	return nil
+}
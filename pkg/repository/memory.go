@@ -0,0 +1,367 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// MemoryTxManager is the multi-version store shared by the memory
+// repositories. Every committed write creates a new version of its row, so a
+// transaction keeps reading the snapshot it began with while its own writes
+// stay in a private write set until Commit. Commit fails with
+// ErrTransactionConflict when another transaction committed one of the same
+// rows after the snapshot was taken.
+type MemoryTxManager struct {
+	mu      sync.RWMutex
+	version uint64
+	tables  map[string]map[uuid.UUID][]memoryVersion
+	active  map[*memoryTx]struct{}
+	stale   map[memoryRowKey]struct{}
+}
+
+type memoryVersion struct {
+	version uint64
+	value   any // nil marks a deleted row
+}
+
+type memoryRowKey struct {
+	table string
+	id    uuid.UUID
+}
+
+func NewMemoryTxManager() *MemoryTxManager {
+	return &MemoryTxManager{
+		tables: make(map[string]map[uuid.UUID][]memoryVersion),
+		active: make(map[*memoryTx]struct{}),
+		stale:  make(map[memoryRowKey]struct{}),
+	}
+}
+
+func (m *MemoryTxManager) BeginTransaction(ctx context.Context) (context.Context, error) {
+	return beginScope(ctx, m, func(ctx context.Context) (transaction, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		tx := &memoryTx{
+			manager:  m,
+			snapshot: m.version,
+			readOnly: readOnlyFromContext(ctx),
+			writes:   make(map[memoryRowKey]any),
+		}
+		m.active[tx] = struct{}{}
+		return tx, nil
+	})
+}
+
+func (m *MemoryTxManager) Commit(ctx context.Context) error {
+	return commitScope(ctx, m)
+}
+
+func (m *MemoryTxManager) Abort(ctx context.Context) {
+	abortScope(ctx, m)
+}
+
+// update runs fn in the transaction of ctx, or in a transaction of its own
+// which is committed right after fn succeeds.
+func (m *MemoryTxManager) update(ctx context.Context, fn func(tx *memoryTx) error) error {
+	tx, ok, err := txFromContext[*memoryTx](ctx, m)
+	if err != nil {
+		return err
+	}
+	if ok {
+		if tx.readOnly {
+			return ErrTransactionReadOnly
+		}
+		return fn(tx)
+	}
+
+	ctx, err = m.BeginTransaction(withReadOnly(ctx, false))
+	if err != nil {
+		return err
+	}
+	defer m.Abort(ctx)
+
+	tx, _, _ = txFromContext[*memoryTx](ctx, m)
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return m.Commit(ctx)
+}
+
+func (m *MemoryTxManager) get(ctx context.Context, key memoryRowKey) (any, bool, error) {
+	tx, ok, err := txFromContext[*memoryTx](ctx, m)
+	if err != nil {
+		return nil, false, err
+	}
+	if ok {
+		v, ok := tx.get(key)
+		return v, ok, nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	v, ok := m.visible(key, m.version)
+	return v, ok, nil
+}
+
+func (m *MemoryTxManager) list(ctx context.Context, table string) ([]any, error) {
+	tx, ok, err := txFromContext[*memoryTx](ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return tx.list(table), nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	values := make([]any, 0)
+	for id := range m.tables[table] {
+		if v, ok := m.visible(memoryRowKey{table: table, id: id}, m.version); ok {
+			values = append(values, v)
+		}
+	}
+	return values, nil
+}
+
+// visible returns the newest version of key committed at or before snapshot.
+// m.mu must be held.
+func (m *MemoryTxManager) visible(key memoryRowKey, snapshot uint64) (any, bool) {
+	versions := m.tables[key.table][key.id]
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].version <= snapshot {
+			return versions[i].value, versions[i].value != nil
+		}
+	}
+
+	return nil, false
+}
+
+func (m *MemoryTxManager) latestVersion(key memoryRowKey) uint64 {
+	versions := m.tables[key.table][key.id]
+	if len(versions) == 0 {
+		return 0
+	}
+
+	return versions[len(versions)-1].version
+}
+
+// collect drops row versions which no active transaction can read anymore.
+// m.mu must be held.
+func (m *MemoryTxManager) collect() {
+	oldest := m.version
+	for tx := range m.active {
+		if tx.snapshot < oldest {
+			oldest = tx.snapshot
+		}
+	}
+
+	for key := range m.stale {
+		rows := m.tables[key.table]
+		versions := rows[key.id]
+
+		keep := 0
+		for i, v := range versions {
+			if v.version <= oldest {
+				keep = i
+			}
+		}
+		versions = versions[keep:]
+
+		switch {
+		case len(versions) == 1 && versions[0].value == nil && versions[0].version <= oldest:
+			delete(rows, key.id)
+			delete(m.stale, key)
+		case len(versions) == 1:
+			rows[key.id] = versions
+			delete(m.stale, key)
+		default:
+			rows[key.id] = versions
+		}
+	}
+}
+
+type memoryTx struct {
+	manager  *MemoryTxManager
+	snapshot uint64
+	readOnly bool
+
+	mu     sync.Mutex
+	writes map[memoryRowKey]any
+}
+
+func (tx *memoryTx) get(key memoryRowKey) (any, bool) {
+	tx.mu.Lock()
+	v, written := tx.writes[key]
+	tx.mu.Unlock()
+	if written {
+		return v, v != nil
+	}
+
+	tx.manager.mu.RLock()
+	defer tx.manager.mu.RUnlock()
+	return tx.manager.visible(key, tx.snapshot)
+}
+
+func (tx *memoryTx) list(table string) []any {
+	tx.mu.Lock()
+	writes := make(map[uuid.UUID]any)
+	for key, v := range tx.writes {
+		if key.table == table {
+			writes[key.id] = v
+		}
+	}
+	tx.mu.Unlock()
+
+	tx.manager.mu.RLock()
+	defer tx.manager.mu.RUnlock()
+
+	values := make([]any, 0)
+	for id := range tx.manager.tables[table] {
+		if _, written := writes[id]; written {
+			continue
+		}
+		key := memoryRowKey{table: table, id: id}
+		if v, ok := tx.manager.visible(key, tx.snapshot); ok {
+			values = append(values, v)
+		}
+	}
+	for _, v := range writes {
+		if v != nil {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
+
+func (tx *memoryTx) put(key memoryRowKey, v any) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.writes[key] = v
+}
+
+func (tx *memoryTx) commit() error {
+	m := tx.manager
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.active, tx)
+	defer m.collect()
+
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	if len(tx.writes) == 0 {
+		return nil
+	}
+
+	for key := range tx.writes {
+		if m.latestVersion(key) > tx.snapshot {
+			return ErrTransactionConflict
+		}
+	}
+
+	m.version++
+	for key, v := range tx.writes {
+		rows, ok := m.tables[key.table]
+		if !ok {
+			rows = make(map[uuid.UUID][]memoryVersion)
+			m.tables[key.table] = rows
+		}
+		rows[key.id] = append(rows[key.id], memoryVersion{version: m.version, value: v})
+		if len(rows[key.id]) > 1 || v == nil {
+			m.stale[key] = struct{}{}
+		}
+	}
+
+	return nil
+}
+
+func (tx *memoryTx) rollback() {
+	m := tx.manager
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.active, tx)
+	m.collect()
+}
+
+// memoryTable is a typed view of one table of a MemoryTxManager. Values are
+// copied in and out so callers never share memory with the store.
+type memoryTable[T any] struct {
+	name    string
+	manager *MemoryTxManager
+}
+
+func newMemoryTable[T any](manager *MemoryTxManager, name string) memoryTable[T] {
+	return memoryTable[T]{
+		name:    name,
+		manager: manager,
+	}
+}
+
+func (t memoryTable[T]) key(id uuid.UUID) memoryRowKey {
+	return memoryRowKey{table: t.name, id: id}
+}
+
+func (t memoryTable[T]) get(ctx context.Context, id uuid.UUID) (*T, error) {
+	v, ok, err := t.manager.get(ctx, t.key(id))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("not found id: " + id.String())
+	}
+
+	copied := *v.(*T)
+	return &copied, nil
+}
+
+func (t memoryTable[T]) list(ctx context.Context, match func(*T) bool) ([]*T, error) {
+	values, err := t.manager.list(ctx, t.name)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*T, 0)
+	for _, v := range values {
+		copied := *v.(*T)
+		if match(&copied) {
+			list = append(list, &copied)
+		}
+	}
+
+	return list, nil
+}
+
+func (t memoryTable[T]) put(tx *memoryTx, id uuid.UUID, v *T) *T {
+	stored := *v
+	tx.put(t.key(id), &stored)
+
+	copied := *v
+	return &copied
+}
+
+// replace overwrites an existing row and fails with ErrNotFound when there is
+// none.
+func (t memoryTable[T]) replace(tx *memoryTx, id uuid.UUID, v *T) (*T, error) {
+	if _, ok := tx.get(t.key(id)); !ok {
+		return nil, fmt.Errorf("%w id: %s", ErrNotFound, id.String())
+	}
+
+	return t.put(tx, id, v), nil
+}
+
+func (t memoryTable[T]) delete(tx *memoryTx, id uuid.UUID) (*T, error) {
+	v, ok := tx.get(t.key(id))
+	if !ok {
+		return nil, errors.New("not found id: " + id.String())
+	}
+	tx.put(t.key(id), nil)
+
+	copied := *v.(*T)
+	return &copied, nil
+}
@@ -0,0 +1,335 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+)
+
+func TestMemoryTxManagerCommitVisibility(t *testing.T) {
+	ctx := context.Background()
+	txManager := NewMemoryTxManager()
+	repo := NewMemoryUserRepo(txManager)
+
+	txCtx, err := repo.BeginTransaction(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Abort(txCtx)
+
+	created, err := repo.CreateUser(txCtx, &domain.User{Nickname: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.FindUserByID(txCtx, created.ID); err != nil {
+		t.Fatalf("transaction does not see its own write: %v", err)
+	}
+	if _, err := repo.FindUserByID(ctx, created.ID); err == nil {
+		t.Fatal("uncommitted write is visible outside the transaction")
+	}
+
+	if err := repo.Commit(txCtx); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := repo.FindUserByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("committed write is not visible: %v", err)
+	}
+	if found.Nickname != "alice" {
+		t.Fatalf("nickname = %q, want %q", found.Nickname, "alice")
+	}
+}
+
+func TestMemoryTxManagerSnapshotIsolation(t *testing.T) {
+	ctx := context.Background()
+	txManager := NewMemoryTxManager()
+	repo := NewMemoryUserRepo(txManager)
+
+	reader, err := repo.BeginTransaction(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Abort(reader)
+
+	created, err := repo.CreateUser(ctx, &domain.User{Nickname: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.FindUserByID(reader, created.ID); err == nil {
+		t.Fatal("transaction sees a row committed after its snapshot")
+	}
+}
+
+func TestMemoryTxManagerAbortRollback(t *testing.T) {
+	ctx := context.Background()
+	txManager := NewMemoryTxManager()
+	repo := NewMemoryUserRepo(txManager)
+
+	existing, err := repo.CreateUser(ctx, &domain.User{Nickname: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txCtx, err := repo.BeginTransaction(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, err := repo.CreateUser(txCtx, &domain.User{Nickname: "bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	renamed := *existing
+	renamed.Nickname = "mallory"
+	if _, err := repo.UpdateUser(txCtx, &renamed); err != nil {
+		t.Fatal(err)
+	}
+	repo.Abort(txCtx)
+
+	if _, err := repo.FindUserByID(ctx, created.ID); err == nil {
+		t.Fatal("aborted create is visible")
+	}
+	found, err := repo.FindUserByID(ctx, existing.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Nickname != "alice" {
+		t.Fatalf("nickname = %q after abort, want %q", found.Nickname, "alice")
+	}
+
+	if err := repo.Commit(txCtx); !errors.Is(err, ErrTransactionDone) {
+		t.Fatalf("commit after abort = %v, want %v", err, ErrTransactionDone)
+	}
+	if len(txManager.active) != 0 {
+		t.Fatalf("%d transactions still active after abort", len(txManager.active))
+	}
+}
+
+func TestMemoryTxManagerWriteConflict(t *testing.T) {
+	ctx := context.Background()
+	txManager := NewMemoryTxManager()
+	repo := NewMemoryUserRepo(txManager)
+
+	user, err := repo.CreateUser(ctx, &domain.User{Nickname: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := repo.BeginTransaction(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Abort(first)
+	second, err := repo.BeginTransaction(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Abort(second)
+
+	for txCtx, nickname := range map[context.Context]string{first: "bob", second: "carol"} {
+		renamed := *user
+		renamed.Nickname = nickname
+		if _, err := repo.UpdateUser(txCtx, &renamed); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := repo.Commit(first); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Commit(second); !errors.Is(err, ErrTransactionConflict) {
+		t.Fatalf("conflicting commit = %v, want %v", err, ErrTransactionConflict)
+	}
+
+	found, err := repo.FindUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Nickname != "bob" {
+		t.Fatalf("nickname = %q, want the first committed %q", found.Nickname, "bob")
+	}
+}
+
+func TestMemoryTxManagerNestedUnitOfWork(t *testing.T) {
+	ctx := context.Background()
+	txManager := NewMemoryTxManager()
+	repo := NewMemoryUserRepo(txManager)
+	uow := NewUnitOfWork(txManager)
+
+	var created *domain.User
+	err := uow.Do(ctx, func(outer context.Context) error {
+		err := uow.Do(outer, func(inner context.Context) error {
+			var err error
+			created, err = repo.CreateUser(inner, &domain.User{Nickname: "alice"})
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		if _, err := repo.FindUserByID(outer, created.ID); err != nil {
+			t.Errorf("outer scope does not see the nested write: %v", err)
+		}
+		if _, err := repo.FindUserByID(ctx, created.ID); err == nil {
+			t.Error("nested write is visible before the outer commit")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.FindUserByID(ctx, created.ID); err != nil {
+		t.Fatalf("nested write is not visible after the outer commit: %v", err)
+	}
+}
+
+func TestMemoryTxManagerNestedAbort(t *testing.T) {
+	ctx := context.Background()
+	txManager := NewMemoryTxManager()
+	repo := NewMemoryUserRepo(txManager)
+	uow := NewUnitOfWork(txManager)
+
+	errInner := errors.New("inner failed")
+	var created *domain.User
+	err := uow.Do(ctx, func(outer context.Context) error {
+		var err error
+		created, err = repo.CreateUser(outer, &domain.User{Nickname: "alice"})
+		if err != nil {
+			return err
+		}
+
+		if err := uow.Do(outer, func(context.Context) error {
+			return errInner
+		}); !errors.Is(err, errInner) {
+			t.Errorf("nested Do = %v, want %v", err, errInner)
+		}
+		return nil
+	})
+	if !errors.Is(err, ErrTransactionAborted) {
+		t.Fatalf("outer Do = %v, want %v", err, ErrTransactionAborted)
+	}
+
+	if _, err := repo.FindUserByID(ctx, created.ID); err == nil {
+		t.Fatal("write of an aborted outer transaction is visible")
+	}
+}
+
+func TestMemoryTxManagerCollectsOldVersions(t *testing.T) {
+	ctx := context.Background()
+	txManager := NewMemoryTxManager()
+	repo := NewMemoryUserRepo(txManager)
+
+	user, err := repo.CreateUser(ctx, &domain.User{Nickname: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := memoryRowKey{table: "users", id: user.ID}
+
+	reader, err := repo.BeginTransaction(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, nickname := range []string{"bob", "carol"} {
+		renamed := *user
+		renamed.Nickname = nickname
+		if _, err := repo.UpdateUser(ctx, &renamed); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := len(txManager.tables["users"][user.ID]); n < 2 {
+		t.Fatalf("%d versions kept while an older snapshot is open, want at least 2", n)
+	}
+	found, err := repo.FindUserByID(reader, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Nickname != "alice" {
+		t.Fatalf("snapshot reads %q, want %q", found.Nickname, "alice")
+	}
+	repo.Abort(reader)
+
+	if n := len(txManager.tables["users"][user.ID]); n != 1 {
+		t.Fatalf("%d versions kept after the snapshot closed, want 1", n)
+	}
+	if _, ok := txManager.stale[key]; ok {
+		t.Fatal("row is still marked stale after collection")
+	}
+
+	if _, err := repo.DeleteUserByID(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := txManager.tables["users"][user.ID]; ok {
+		t.Fatal("deleted row is kept after no snapshot can read it")
+	}
+	if len(txManager.stale) != 0 {
+		t.Fatalf("%d rows still marked stale", len(txManager.stale))
+	}
+}
+
+func TestMemoryUserRepoUpdateMissingUser(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryUserRepo(NewMemoryTxManager())
+
+	missing := &domain.User{ID: uuid.New(), Nickname: "alice"}
+	if _, err := repo.UpdateUser(ctx, missing); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("update of a missing user = %v, want %v", err, ErrNotFound)
+	}
+	if _, err := repo.FindUserByID(ctx, missing.ID); err == nil {
+		t.Fatal("update created the missing user")
+	}
+
+	if err := repo.RestoreUsers(ctx, missing); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.FindUserByID(ctx, missing.ID); err != nil {
+		t.Fatalf("restored user is not found: %v", err)
+	}
+}
+
+func TestMemoryTxManagerReadOnlyUnitOfWork(t *testing.T) {
+	ctx := context.Background()
+	txManager := NewMemoryTxManager()
+	repo := NewMemoryUserRepo(txManager)
+	uow := NewUnitOfWork(txManager)
+
+	created, err := repo.CreateUser(ctx, &domain.User{Nickname: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = uow.View(ctx, func(ctx context.Context) error {
+		if _, err := repo.FindUserByID(ctx, created.ID); err != nil {
+			t.Errorf("read in a read-only unit of work failed: %v", err)
+		}
+		if _, err := repo.CreateUser(ctx, &domain.User{Nickname: "bob"}); !errors.Is(err, ErrTransactionReadOnly) {
+			t.Errorf("write in a read-only unit of work = %v, want %v", err, ErrTransactionReadOnly)
+		}
+		if err := uow.Do(ctx, func(context.Context) error { return nil }); !errors.Is(err, ErrTransactionReadOnly) {
+			t.Errorf("Do inside View = %v, want %v", err, ErrTransactionReadOnly)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A View inside a Do joins the writable unit of work.
+	err = uow.Do(ctx, func(ctx context.Context) error {
+		return uow.View(ctx, func(ctx context.Context) error {
+			_, err := repo.CreateUser(ctx, &domain.User{Nickname: "carol"})
+			return err
+		})
+	})
+	if err != nil {
+		t.Fatalf("write in a View joining a Do = %v, want success", err)
+	}
+}
@@ -2,169 +2,69 @@ package repository
 
 import (
 	"context"
-	"errors"
-	"sync"
 
 	"github.com/google/uuid"
 
-	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/api"
 	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
 )
 
 type MemoryMindMapRepo struct {
-	mu     sync.RWMutex
-	nodes  map[uuid.UUID]*domain.KeywordNode
-	edges  map[uuid.UUID]*domain.KeywordEdge
-	caches map[uuid.UUID]*mindmapCache
+	txManager *MemoryTxManager
+	nodes     memoryTable[domain.KeywordNode]
+	edges     memoryTable[domain.KeywordEdge]
 }
 
-type mindmapCache struct {
-	mu               sync.RWMutex
-	deferedOperation []func()
-	nodes            map[uuid.UUID]*domain.KeywordNode
-	edges            map[uuid.UUID]*domain.KeywordEdge
-}
-
-func NewMemoryMindMapRepo() *MemoryMindMapRepo {
+func NewMemoryMindMapRepo(txManager *MemoryTxManager) *MemoryMindMapRepo {
 	return &MemoryMindMapRepo{
-		nodes:  make(map[uuid.UUID]*domain.KeywordNode, 1024),
-		edges:  make(map[uuid.UUID]*domain.KeywordEdge, 1024),
-		caches: make(map[uuid.UUID]*mindmapCache, 0),
+		txManager: txManager,
+		nodes:     newMemoryTable[domain.KeywordNode](txManager, "keyword_nodes"),
+		edges:     newMemoryTable[domain.KeywordEdge](txManager, "keyword_edges"),
 	}
 }
 
-func (r *MemoryMindMapRepo) BeginTransaction(ctx context.Context) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return
-	}
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	nodes := make(map[uuid.UUID]*domain.KeywordNode)
-	for k, v := range r.nodes {
-		nodes[k] = v
-	}
-
-	edges := make(map[uuid.UUID]*domain.KeywordEdge)
-	for k, v := range r.edges {
-		edges[k] = v
-	}
-
-	r.caches[requestID] = &mindmapCache{
-		deferedOperation: make([]func(), 0),
-		nodes:            nodes,
-		edges:            edges,
-	}
+func (r *MemoryMindMapRepo) BeginTransaction(ctx context.Context) (context.Context, error) {
+	return r.txManager.BeginTransaction(ctx)
 }
 
-func (r *MemoryMindMapRepo) Commit(ctx context.Context) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return
-	}
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	cache, ok := r.caches[requestID]
-	if !ok {
-		return
-	}
-	for _, operation := range cache.deferedOperation {
-		operation()
-	}
+func (r *MemoryMindMapRepo) Commit(ctx context.Context) error {
+	return r.txManager.Commit(ctx)
 }
 
 func (r *MemoryMindMapRepo) Abort(ctx context.Context) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return
-	}
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	delete(r.caches, requestID)
+	r.txManager.Abort(ctx)
 }
 
 func (r *MemoryMindMapRepo) CreateKeywordNode(
 	ctx context.Context,
 	node *domain.KeywordNode,
 ) (*domain.KeywordNode, error) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return nil, errors.New("not found request id")
-	}
-
-	id, err := uuid.NewRandom()
+	created, err := r.CreateBulkKeywordNodes(ctx, node)
 	if err != nil {
 		return nil, err
 	}
-	node.ID = id
-	copied := *node
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	cache, ok := r.caches[requestID]
-	if !ok {
-		r.nodes[id] = node
-		return &copied, nil
-	}
-	cache.mu.Lock()
-	defer cache.mu.Unlock()
-	cache.nodes[id] = node
-	cache.deferedOperation = append(cache.deferedOperation, func() {
-		r.nodes[id] = node
-	})
 
-	return &copied, nil
+	return created[0], nil
 }
 
 func (r *MemoryMindMapRepo) CreateBulkKeywordNodes(
 	ctx context.Context,
 	bulks ...*domain.KeywordNode,
 ) ([]*domain.KeywordNode, error) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return nil, errors.New("not found request id")
-	}
-
-	nodes := make([]*domain.KeywordNode, 0, len(bulks))
-	for _, node := range bulks {
-		id, err := uuid.NewRandom()
-		if err != nil {
-			return nil, err
-		}
-		node.ID = id
-		nodes = append(nodes, node)
-	}
-
-	nodeCopies := make([]*domain.KeywordNode, 0, len(nodes))
-	for _, node := range nodes {
-		copied := *node
-		nodeCopies = append(nodeCopies, &copied)
-	}
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	cache, ok := r.caches[requestID]
-	if !ok {
-		for _, node := range nodes {
-			r.nodes[node.ID] = node
-		}
-		return nodeCopies, nil
-	}
-
-	cache.mu.Lock()
-	defer cache.mu.Unlock()
-	for _, node := range nodes {
-		cache.nodes[node.ID] = node
-	}
-	cache.deferedOperation = append(cache.deferedOperation, func() {
-		for _, node := range nodes {
-			r.nodes[node.ID] = node
+	nodeCopies := make([]*domain.KeywordNode, 0, len(bulks))
+	err := r.txManager.update(ctx, func(tx *memoryTx) error {
+		for _, node := range bulks {
+			id, err := uuid.NewRandom()
+			if err != nil {
+				return err
+			}
+			node.ID = id
+			nodeCopies = append(nodeCopies, r.nodes.put(tx, id, node))
 		}
+		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	return nodeCopies, nil
 }
@@ -173,180 +73,105 @@ func (r *MemoryMindMapRepo) FindKeywordNodeByID(
 	ctx context.Context,
 	id uuid.UUID,
 ) (*domain.KeywordNode, error) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return nil, errors.New("not found request id")
-	}
-
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	cache, ok := r.caches[requestID]
-	if !ok {
-		node, ok := r.nodes[id]
-		if !ok {
-			return nil, errors.New("not found id: " + id.String())
-		}
-
-		return node, nil
-	}
-
-	cache.mu.RLock()
-	defer cache.mu.RUnlock()
-	node, ok := cache.nodes[id]
-	if !ok {
-		return nil, errors.New("not found id: " + id.String())
-	}
-
-	return node, nil
+	return r.nodes.get(ctx, id)
 }
 
 func (r *MemoryMindMapRepo) ListKeywordNodeByUser(
 	ctx context.Context,
 	userID uuid.UUID,
 ) ([]*domain.KeywordNode, error) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return nil, errors.New("not found request id")
-	}
-
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	cache, ok := r.caches[requestID]
-	if !ok {
-		nodes := make([]*domain.KeywordNode, 0)
-		for _, n := range r.nodes {
-			if n.UserID == userID {
-				nodes = append(nodes, n)
-			}
-		}
-		return nodes, nil
-	}
-
-	cache.mu.RLock()
-	defer cache.mu.RUnlock()
-	nodes := make([]*domain.KeywordNode, 0)
-	for _, n := range cache.nodes {
-		if n.UserID == userID {
-			nodes = append(nodes, n)
-		}
-	}
-	return nodes, nil
+	return r.nodes.list(ctx, func(n *domain.KeywordNode) bool {
+		return n.UserID == userID
+	})
 }
 
 func (r *MemoryMindMapRepo) ListKeywordNodeByNotionPage(
 	ctx context.Context,
 	notionID uuid.UUID,
 ) ([]*domain.KeywordNode, error) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return nil, errors.New("not found request id")
-	}
+	return r.nodes.list(ctx, func(n *domain.KeywordNode) bool {
+		return n.NotionPageID == notionID
+	})
+}
 
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	cache, ok := r.caches[requestID]
-	if !ok {
-		nodes := make([]*domain.KeywordNode, 0)
-		for _, n := range r.nodes {
-			if n.NotionPageID == notionID {
-				nodes = append(nodes, n)
-			}
+func (r *MemoryMindMapRepo) ListKeywordNodes(ctx context.Context) ([]*domain.KeywordNode, error) {
+	return r.nodes.list(ctx, func(*domain.KeywordNode) bool {
+		return true
+	})
+}
+
+// RestoreBulkKeywordNodes stores the nodes under their own ids.
+func (r *MemoryMindMapRepo) RestoreBulkKeywordNodes(
+	ctx context.Context,
+	bulks ...*domain.KeywordNode,
+) error {
+	return r.txManager.update(ctx, func(tx *memoryTx) error {
+		for _, node := range bulks {
+			r.nodes.put(tx, node.ID, node)
 		}
-		return nodes, nil
+		return nil
+	})
+}
+
+// MarkKeywordNodesStale flags the nodes extracted from the given notion pages
+// and returns how many were not flagged yet.
+func (r *MemoryMindMapRepo) MarkKeywordNodesStale(ctx context.Context, notionIDs []uuid.UUID) (int, error) {
+	pages := make(map[uuid.UUID]bool, len(notionIDs))
+	for _, id := range notionIDs {
+		pages[id] = true
 	}
 
-	cache.mu.RLock()
-	defer cache.mu.RUnlock()
-	nodes := make([]*domain.KeywordNode, 0)
-	for _, n := range cache.nodes {
-		if n.NotionPageID == notionID {
-			nodes = append(nodes, n)
+	marked := 0
+	err := r.txManager.update(ctx, func(tx *memoryTx) error {
+		for _, v := range tx.list(r.nodes.name) {
+			node := *v.(*domain.KeywordNode)
+			if node.Stale || !pages[node.NotionPageID] {
+				continue
+			}
+			node.Stale = true
+			r.nodes.put(tx, node.ID, &node)
+			marked++
 		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
-	return nodes, nil
+
+	return marked, nil
 }
 
 func (r *MemoryMindMapRepo) DeleteKeywordNodeByID(
 	ctx context.Context,
 	id uuid.UUID,
 ) (*domain.KeywordNode, error) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return nil, errors.New("not found request id")
-	}
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	cache, ok := r.caches[requestID]
-	if !ok {
-		deleted, ok := r.nodes[id]
-		if !ok {
-			return nil, errors.New("not found id: " + id.String())
-		}
-		delete(r.nodes, id)
-		return deleted, nil
-	}
-
-	cache.mu.Lock()
-	defer cache.mu.Unlock()
-	deleted, ok := cache.nodes[id]
-	if !ok {
-		return nil, errors.New("not found id: " + id.String())
+	deleted, err := r.DeleteBulkKeywordNodes(ctx, []uuid.UUID{id})
+	if err != nil {
+		return nil, err
 	}
-	delete(cache.nodes, id)
-	cache.deferedOperation = append(cache.deferedOperation, func() {
-		delete(r.nodes, id)
-	})
 
-	return deleted, nil
+	return deleted[0], nil
 }
 
 func (r *MemoryMindMapRepo) DeleteBulkKeywordNodes(
 	ctx context.Context,
 	ids []uuid.UUID,
 ) ([]*domain.KeywordNode, error) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return nil, errors.New("not found request id")
-	}
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	cache, ok := r.caches[requestID]
-	if !ok {
-		deleted := make([]*domain.KeywordNode, 0, len(ids))
+	deleted := make([]*domain.KeywordNode, 0, len(ids))
+	err := r.txManager.update(ctx, func(tx *memoryTx) error {
 		for _, id := range ids {
-			node, ok := r.nodes[id]
-			if !ok {
-				return nil, errors.New("not found id: " + id.String())
+			node, err := r.nodes.delete(tx, id)
+			if err != nil {
+				return err
 			}
 			deleted = append(deleted, node)
 		}
-		for _, id := range ids {
-			delete(r.nodes, id)
-		}
-		return deleted, nil
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	cache.mu.Lock()
-	defer cache.mu.Unlock()
-	deleted := make([]*domain.KeywordNode, 0, len(ids))
-	for _, id := range ids {
-		node, ok := cache.nodes[id]
-		if !ok {
-			return nil, errors.New("not found id: " + id.String())
-		}
-		deleted = append(deleted, node)
-	}
-	for _, id := range ids {
-		delete(cache.nodes, id)
-	}
-	cache.deferedOperation = append(cache.deferedOperation, func() {
-		for _, id := range ids {
-			delete(r.nodes, id)
-		}
-	})
 	return deleted, nil
 }
 
@@ -354,81 +179,33 @@ func (r *MemoryMindMapRepo) CreateKeywordEdge(
 	ctx context.Context,
 	edge *domain.KeywordEdge,
 ) (*domain.KeywordEdge, error) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return nil, errors.New("not found request id")
-	}
-
-	id, err := uuid.NewRandom()
+	created, err := r.CreateBulkKeywordEdges(ctx, edge)
 	if err != nil {
 		return nil, err
 	}
-	edge.ID = id
-	copied := *edge
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	cache, ok := r.caches[requestID]
-	if !ok {
-		r.edges[id] = edge
-		return &copied, nil
-	}
-
-	cache.mu.Lock()
-	defer cache.mu.Unlock()
-	cache.edges[id] = edge
-	cache.deferedOperation = append(cache.deferedOperation, func() {
-		r.edges[id] = edge
-	})
 
-	return &copied, nil
+	return created[0], nil
 }
 
 func (r *MemoryMindMapRepo) CreateBulkKeywordEdges(
 	ctx context.Context,
 	bulks ...*domain.KeywordEdge,
 ) ([]*domain.KeywordEdge, error) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return nil, errors.New("not found request id")
-	}
-
-	edges := make([]*domain.KeywordEdge, 0, len(bulks))
-	for _, edge := range bulks {
-		id, err := uuid.NewRandom()
-		if err != nil {
-			return nil, err
-		}
-		edge.ID = id
-		edges = append(edges, edge)
-	}
-
-	edgeCopies := make([]*domain.KeywordEdge, 0, len(edges))
-	for _, edge := range edges {
-		copied := *edge
-		edgeCopies = append(edgeCopies, &copied)
-	}
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	cache, ok := r.caches[requestID]
-	if !ok {
-		for _, edge := range edges {
-			r.edges[edge.ID] = edge
-		}
-		return edgeCopies, nil
-	}
-
-	cache.mu.Lock()
-	defer cache.mu.Unlock()
-	for _, edge := range edges {
-		cache.edges[edge.ID] = edge
-	}
-	cache.deferedOperation = append(cache.deferedOperation, func() {
-		for _, edge := range edges {
-			r.edges[edge.ID] = edge
+	edgeCopies := make([]*domain.KeywordEdge, 0, len(bulks))
+	err := r.txManager.update(ctx, func(tx *memoryTx) error {
+		for _, edge := range bulks {
+			id, err := uuid.NewRandom()
+			if err != nil {
+				return err
+			}
+			edge.ID = id
+			edgeCopies = append(edgeCopies, r.edges.put(tx, id, edge))
 		}
+		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	return edgeCopies, nil
 }
@@ -437,119 +214,60 @@ func (r *MemoryMindMapRepo) ListKeywordEdgeByUser(
 	ctx context.Context,
 	userID uuid.UUID,
 ) ([]*domain.KeywordEdge, error) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return nil, errors.New("not found request id")
-	}
-
-	r.mu.RLock()
-	defer r.mu.RUnlock()
+	return r.edges.list(ctx, func(e *domain.KeywordEdge) bool {
+		return e.UserID == userID
+	})
+}
 
-	cache, ok := r.caches[requestID]
-	if !ok {
-		edges := make([]*domain.KeywordEdge, 0)
-		for _, e := range r.edges {
-			if e.UserID == userID {
-				edges = append(edges, e)
-			}
-		}
-		return edges, nil
-	}
+func (r *MemoryMindMapRepo) ListKeywordEdges(ctx context.Context) ([]*domain.KeywordEdge, error) {
+	return r.edges.list(ctx, func(*domain.KeywordEdge) bool {
+		return true
+	})
+}
 
-	cache.mu.RLock()
-	defer cache.mu.RUnlock()
-	edges := make([]*domain.KeywordEdge, 0)
-	for _, e := range cache.edges {
-		if e.UserID == userID {
-			edges = append(edges, e)
+// RestoreBulkKeywordEdges stores the edges under their own ids.
+func (r *MemoryMindMapRepo) RestoreBulkKeywordEdges(
+	ctx context.Context,
+	bulks ...*domain.KeywordEdge,
+) error {
+	return r.txManager.update(ctx, func(tx *memoryTx) error {
+		for _, edge := range bulks {
+			r.edges.put(tx, edge.ID, edge)
 		}
-	}
-
-	return edges, nil
+		return nil
+	})
 }
 
 func (r *MemoryMindMapRepo) DeleteKeywordEdgeByID(
 	ctx context.Context,
 	id uuid.UUID,
 ) (*domain.KeywordEdge, error) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return nil, errors.New("not found request id")
-	}
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	cache, ok := r.caches[requestID]
-	if !ok {
-		deleted, ok := r.edges[id]
-		if !ok {
-			return nil, errors.New("not found id: " + id.String())
-		}
-		delete(r.edges, id)
-		return deleted, nil
-	}
-
-	cache.mu.Lock()
-	defer cache.mu.Unlock()
-	deleted, ok := cache.edges[id]
-	if !ok {
-		return nil, errors.New("not found id: " + id.String())
+	deleted, err := r.DeleteBulkKeywordEdges(ctx, []uuid.UUID{id})
+	if err != nil {
+		return nil, err
 	}
-	delete(cache.edges, id)
-	cache.deferedOperation = append(cache.deferedOperation, func() {
-		delete(r.edges, id)
-	})
 
-	return deleted, nil
+	return deleted[0], nil
 }
 
 func (r *MemoryMindMapRepo) DeleteBulkKeywordEdges(
 	ctx context.Context,
 	ids []uuid.UUID,
 ) ([]*domain.KeywordEdge, error) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return nil, errors.New("not found request id")
-	}
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	cache, ok := r.caches[requestID]
-	if !ok {
-		deleted := make([]*domain.KeywordEdge, 0, len(ids))
-		for _, id := range ids {
-			node, ok := r.edges[id]
-			if !ok {
-				return nil, errors.New("not found id: " + id.String())
-			}
-			deleted = append(deleted, node)
-		}
-		for _, id := range ids {
-			delete(r.edges, id)
-		}
-		return deleted, nil
-	}
-
-	cache.mu.Lock()
-	defer cache.mu.Unlock()
 	deleted := make([]*domain.KeywordEdge, 0, len(ids))
-	for _, id := range ids {
-		node, ok := cache.edges[id]
-		if !ok {
-			return nil, errors.New("not found id: " + id.String())
-		}
-		deleted = append(deleted, node)
-	}
-	for _, id := range ids {
-		delete(cache.edges, id)
-	}
-	cache.deferedOperation = append(cache.deferedOperation, func() {
+	err := r.txManager.update(ctx, func(tx *memoryTx) error {
 		for _, id := range ids {
-			delete(r.edges, id)
+			edge, err := r.edges.delete(tx, id)
+			if err != nil {
+				return err
+			}
+			deleted = append(deleted, edge)
 		}
+		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	return deleted, nil
 }
@@ -2,151 +2,71 @@ package repository
 
 import (
 	"context"
-	"errors"
-	"sync"
 
 	"github.com/google/uuid"
 
-	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/api"
 	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
 )
 
 type MemoryNotionPageRepo struct {
-	mu     sync.RWMutex
-	pages  map[uuid.UUID]*domain.NotionPage
-	caches map[uuid.UUID]*notionPageCache
+	txManager *MemoryTxManager
+	pages     memoryTable[domain.NotionPage]
 }
 
-type notionPageCache struct {
-	mu               sync.RWMutex
-	deferedOperation []func()
-	pages            map[uuid.UUID]*domain.NotionPage
-}
-
-func NewMemoryNotionPageRepo() *MemoryNotionPageRepo {
+func NewMemoryNotionPageRepo(txManager *MemoryTxManager) *MemoryNotionPageRepo {
 	return &MemoryNotionPageRepo{
-		pages:  make(map[uuid.UUID]*domain.NotionPage, 1024),
-		caches: make(map[uuid.UUID]*notionPageCache, 0),
+		txManager: txManager,
+		pages:     newMemoryTable[domain.NotionPage](txManager, "notion_pages"),
 	}
 }
 
-func (r *MemoryNotionPageRepo) BeginTransaction(ctx context.Context) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return
-	}
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	pages := make(map[uuid.UUID]*domain.NotionPage)
-	for k, v := range r.pages {
-		pages[k] = v
-	}
-
-	r.caches[requestID] = &notionPageCache{
-		deferedOperation: make([]func(), 0),
-		pages:            pages,
-	}
+func (r *MemoryNotionPageRepo) BeginTransaction(ctx context.Context) (context.Context, error) {
+	return r.txManager.BeginTransaction(ctx)
 }
 
-func (r *MemoryNotionPageRepo) Commit(ctx context.Context) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return
-	}
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	cache, ok := r.caches[requestID]
-	if !ok {
-		return
-	}
-	for _, operation := range cache.deferedOperation {
-		operation()
-	}
+func (r *MemoryNotionPageRepo) Commit(ctx context.Context) error {
+	return r.txManager.Commit(ctx)
 }
 
 func (r *MemoryNotionPageRepo) Abort(ctx context.Context) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return
-	}
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	delete(r.caches, requestID)
+	r.txManager.Abort(ctx)
 }
 
 func (r *MemoryNotionPageRepo) CreateNotionPage(
 	ctx context.Context,
 	page *domain.NotionPage,
 ) (*domain.NotionPage, error) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return nil, errors.New("not found request id")
-	}
-
-	id, err := uuid.NewRandom()
+	created, err := r.CreateNotionPages(ctx, []*domain.NotionPage{page})
 	if err != nil {
 		return nil, err
 	}
-	page.ID = id
-	copied := *page
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	cache, ok := r.caches[requestID]
-	if !ok {
-		r.pages[id] = page
-		return &copied, nil
-	}
 
-	cache.mu.Lock()
-	defer cache.mu.Unlock()
-	cache.pages[id] = page
-	cache.deferedOperation = append(cache.deferedOperation, func() {
-		r.pages[id] = page
-	})
-	return &copied, nil
+	return created[0], nil
 }
 
 func (r *MemoryNotionPageRepo) CreateNotionPages(
 	ctx context.Context,
 	pages []*domain.NotionPage,
 ) ([]*domain.NotionPage, error) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return nil, errors.New("not found request id")
-	}
-
 	createdPages := make([]*domain.NotionPage, 0, len(pages))
-	
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	cache, ok := r.caches[requestID]
-	
-	for _, page := range pages {
-		id, err := uuid.NewRandom()
-		if err != nil {
-			return nil, err
-		}
-		page.ID = id
-		copied := *page
-		createdPages = append(createdPages, &copied)
-		
-		if !ok {
-			r.pages[id] = page
-		} else {
-			cache.mu.Lock()
-			cache.pages[id] = page
-			cache.deferedOperation = append(cache.deferedOperation, func() {
-				r.pages[id] = page
-			})
-			cache.mu.Unlock()
+	err := r.txManager.update(ctx, func(tx *memoryTx) error {
+		for _, page := range pages {
+			if page == nil {
+				continue
+			}
+			id, err := uuid.NewRandom()
+			if err != nil {
+				return err
+			}
+			page.ID = id
+			createdPages = append(createdPages, r.pages.put(tx, id, page))
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	
+
 	return createdPages, nil
 }
 
@@ -154,122 +74,64 @@ func (r *MemoryNotionPageRepo) FindAllNotionPagesByUser(
 	ctx context.Context,
 	userID uuid.UUID,
 ) ([]*domain.NotionPage, error) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return nil, errors.New("not found request id")
-	}
-
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	cache, ok := r.caches[requestID]
-	if !ok {
-		pages := make([]*domain.NotionPage, 0)
-		for _, p := range r.pages {
-			if p.UserID == userID {
-				pages = append(pages, p)
-			}
-		}
-		return pages, nil
-	}
-
-	cache.mu.RLock()
-	defer cache.mu.RUnlock()
-	pages := make([]*domain.NotionPage, 0)
-	for _, p := range cache.pages {
-		if p.UserID == userID {
-			pages = append(pages, p)
-		}
-	}
-	return pages, nil
+	return r.pages.list(ctx, func(p *domain.NotionPage) bool {
+		return p.UserID == userID
+	})
 }
 
 func (r *MemoryNotionPageRepo) FindNotionPageByID(
 	ctx context.Context,
 	id uuid.UUID,
 ) (*domain.NotionPage, error) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return nil, errors.New("not found request id")
-	}
-
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	cache, ok := r.caches[requestID]
-	if !ok {
-		page, ok := r.pages[id]
-		if !ok {
-			return nil, errors.New("not found id: " + id.String())
-		}
-		return page, nil
-	}
+	return r.pages.get(ctx, id)
+}
 
-	cache.mu.RLock()
-	defer cache.mu.RUnlock()
-	page, ok := cache.pages[id]
-	if !ok {
-		return nil, errors.New("not found id: " + id.String())
-	}
-	return page, nil
+func (r *MemoryNotionPageRepo) ListNotionPages(ctx context.Context) ([]*domain.NotionPage, error) {
+	return r.pages.list(ctx, func(*domain.NotionPage) bool {
+		return true
+	})
 }
 
 func (r *MemoryNotionPageRepo) UpdateNotionPage(
 	ctx context.Context,
 	page *domain.NotionPage,
 ) (*domain.NotionPage, error) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return nil, errors.New("not found request id")
+	var updated *domain.NotionPage
+	err := r.txManager.update(ctx, func(tx *memoryTx) error {
+		var err error
+		updated, err = r.pages.replace(tx, page.ID, page)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	copied := *page
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	cache, ok := r.caches[requestID]
-	if !ok {
-		r.pages[page.ID] = page
-		return &copied, nil
-	}
+	return updated, nil
+}
 
-	cache.mu.Lock()
-	defer cache.mu.Unlock()
-	cache.pages[page.ID] = page
-	cache.deferedOperation = append(cache.deferedOperation, func() {
-		r.pages[page.ID] = page
+// RestoreNotionPages stores the pages under their own ids.
+func (r *MemoryNotionPageRepo) RestoreNotionPages(ctx context.Context, pages ...*domain.NotionPage) error {
+	return r.txManager.update(ctx, func(tx *memoryTx) error {
+		for _, page := range pages {
+			r.pages.put(tx, page.ID, page)
+		}
+		return nil
 	})
-	return &copied, nil
 }
 
 func (r *MemoryNotionPageRepo) DeleteNotionPageByID(
 	ctx context.Context,
 	id uuid.UUID,
 ) (*domain.NotionPage, error) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return nil, errors.New("not found request id")
-	}
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	cache, ok := r.caches[requestID]
-	if !ok {
-		deleted, ok := r.pages[id]
-		if !ok {
-			return nil, errors.New("not found id: " + id.String())
-		}
-		delete(r.pages, id)
-		return deleted, nil
+	var deleted *domain.NotionPage
+	err := r.txManager.update(ctx, func(tx *memoryTx) error {
+		var err error
+		deleted, err = r.pages.delete(tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	cache.mu.Lock()
-	defer cache.mu.Unlock()
-	deleted, ok := cache.pages[id]
-	if !ok {
-		return nil, errors.New("not found id: " + id.String())
-	}
-	delete(cache.pages, id)
-	cache.deferedOperation = append(cache.deferedOperation, func() {
-		delete(r.pages, id)
-	})
 	return deleted, nil
 }
@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/config"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/migration"
+)
+
+func NewPostgresDB(cfg *config.DBConfig) (*sql.DB, error) {
+	db, err := sql.Open("postgres", postgresDSN(cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// migratePostgres applies pending schema migrations, or only reports them when
+// requireMigrated is set so that operators can migrate explicitly.
+func migratePostgres(ctx context.Context, db *sql.DB, requireMigrated bool) error {
+	migrator, err := migration.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	if !requireMigrated {
+		_, err := migrator.Up(ctx)
+		return err
+	}
+
+	pending, err := migrator.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf(
+			"database schema is behind by %d migration(s), run `migrate up` first",
+			len(pending),
+		)
+	}
+
+	return nil
+}
+
+func postgresDSN(cfg *config.DBConfig) string {
+	dsn := &url.URL{
+		Scheme: "postgres",
+		Host:   cfg.Host,
+		Path:   "/" + cfg.DBName,
+	}
+	if cfg.Port != "" {
+		dsn.Host = fmt.Sprintf("%s:%s", cfg.Host, cfg.Port)
+	}
+	if cfg.User != "" {
+		dsn.User = url.UserPassword(cfg.User, cfg.Password)
+	}
+
+	q := dsn.Query()
+	if cfg.SSLMode != "" {
+		q.Set("sslmode", cfg.SSLMode)
+	}
+	if cfg.TimeZone != "" {
+		q.Set("timezone", cfg.TimeZone)
+	}
+	dsn.RawQuery = q.Encode()
+
+	return dsn.String()
+}
+
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// PostgresTxManager begins the sql transactions carried by the contexts passed
+// to the postgres repositories sharing it.
+type PostgresTxManager struct {
+	db *sql.DB
+}
+
+func NewPostgresTxManager(db *sql.DB) *PostgresTxManager {
+	return &PostgresTxManager{
+		db: db,
+	}
+}
+
+type postgresTx struct {
+	tx *sql.Tx
+}
+
+func (t *postgresTx) commit() error {
+	return t.tx.Commit()
+}
+
+func (t *postgresTx) rollback() {
+	if err := t.tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+		log.Printf("fail to rollback transaction: %v", err)
+	}
+}
+
+func (m *PostgresTxManager) BeginTransaction(ctx context.Context) (context.Context, error) {
+	return beginScope(ctx, m, func(ctx context.Context) (transaction, error) {
+		tx, err := m.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: readOnlyFromContext(ctx)})
+		if err != nil {
+			return nil, err
+		}
+		return &postgresTx{tx: tx}, nil
+	})
+}
+
+func (m *PostgresTxManager) Commit(ctx context.Context) error {
+	return commitScope(ctx, m)
+}
+
+func (m *PostgresTxManager) Abort(ctx context.Context) {
+	abortScope(ctx, m)
+}
+
+// querier returns the transaction carried by ctx or the database itself.
+func (m *PostgresTxManager) querier(ctx context.Context) (querier, error) {
+	tx, ok, err := txFromContext[*postgresTx](ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return tx.tx, nil
+	}
+
+	return m.db, nil
+}
+
+// run executes fn inside the transaction of ctx when there is one, otherwise
+// inside a short-lived transaction so that bulk statements stay all-or-nothing.
+func (m *PostgresTxManager) run(ctx context.Context, fn func(q querier) error) error {
+	tx, ok, err := txFromContext[*postgresTx](ctx, m)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return fn(tx.tx)
+	}
+
+	sqlTx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(sqlTx); err != nil {
+		_ = sqlTx.Rollback()
+		return err
+	}
+
+	return sqlTx.Commit()
+}
+
+func uuidArray(ids []uuid.UUID) pq.StringArray {
+	arr := make(pq.StringArray, 0, len(ids))
+	for _, id := range ids {
+		arr = append(arr, id.String())
+	}
+
+	return arr
+}
+
+func (m *PostgresTxManager) exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	q, err := m.querier(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.ExecContext(ctx, query, args...)
+}
+
+func (m *PostgresTxManager) query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	q, err := m.querier(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.QueryContext(ctx, query, args...)
+}
+
+func (m *PostgresTxManager) queryRow(ctx context.Context, query string, args ...any) scanner {
+	q, err := m.querier(ctx)
+	if err != nil {
+		return errRow{err: err}
+	}
+
+	return q.QueryRowContext(ctx, query, args...)
+}
+
+type errRow struct {
+	err error
+}
+
+func (r errRow) Scan(...any) error { return r.err }
+
+// requireAffected turns an update which matched no row into ErrNotFound.
+func requireAffected(result sql.Result, id uuid.UUID) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w id: %s", ErrNotFound, id.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+)
+
+const apiKeyColumns = `id, user_id, name, hint, hash, scopes, created_at, last_used_at`
+
+type PostgresAPIKeyRepo struct {
+	txManager *PostgresTxManager
+}
+
+func NewPostgresAPIKeyRepo(txManager *PostgresTxManager) *PostgresAPIKeyRepo {
+	return &PostgresAPIKeyRepo{
+		txManager: txManager,
+	}
+}
+
+func (r *PostgresAPIKeyRepo) BeginTransaction(ctx context.Context) (context.Context, error) {
+	return r.txManager.BeginTransaction(ctx)
+}
+
+func (r *PostgresAPIKeyRepo) Commit(ctx context.Context) error {
+	return r.txManager.Commit(ctx)
+}
+
+func (r *PostgresAPIKeyRepo) Abort(ctx context.Context) {
+	r.txManager.Abort(ctx)
+}
+
+func (r *PostgresAPIKeyRepo) CreateAPIKey(ctx context.Context, key *domain.APIKey) (*domain.APIKey, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	key.ID = id
+
+	q, err := r.txManager.querier(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := execInsertAPIKey(ctx, q, key); err != nil {
+		return nil, err
+	}
+
+	copied := *key
+	return &copied, nil
+}
+
+func (r *PostgresAPIKeyRepo) FindAPIKeyByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	row := r.txManager.queryRow(ctx,
+		`SELECT `+apiKeyColumns+` FROM api_keys WHERE hash = $1`,
+		hash,
+	)
+
+	key, err := scanAPIKey(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w api key", ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (r *PostgresAPIKeyRepo) ListAPIKeysByUser(ctx context.Context, userID uuid.UUID) ([]*domain.APIKey, error) {
+	return r.listAPIKeys(ctx,
+		`SELECT `+apiKeyColumns+` FROM api_keys WHERE user_id = $1 ORDER BY created_at`,
+		userID,
+	)
+}
+
+func (r *PostgresAPIKeyRepo) ListAPIKeys(ctx context.Context) ([]*domain.APIKey, error) {
+	return r.listAPIKeys(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY created_at`)
+}
+
+func (r *PostgresAPIKeyRepo) listAPIKeys(ctx context.Context, query string, args ...any) ([]*domain.APIKey, error) {
+	rows, err := r.txManager.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]*domain.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// RestoreAPIKeys inserts the keys under their own ids.
+func (r *PostgresAPIKeyRepo) RestoreAPIKeys(ctx context.Context, keys ...*domain.APIKey) error {
+	return r.txManager.run(ctx, func(q querier) error {
+		for _, key := range keys {
+			if err := execInsertAPIKey(ctx, q, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *PostgresAPIKeyRepo) TouchAPIKey(ctx context.Context, id uuid.UUID, at time.Time) error {
+	_, err := r.txManager.exec(ctx, `UPDATE api_keys SET last_used_at = $2 WHERE id = $1`, id, at)
+	return err
+}
+
+func (r *PostgresAPIKeyRepo) DeleteAPIKeyByID(ctx context.Context, id uuid.UUID) (*domain.APIKey, error) {
+	row := r.txManager.queryRow(ctx,
+		`DELETE FROM api_keys WHERE id = $1 RETURNING `+apiKeyColumns,
+		id,
+	)
+
+	deleted, err := scanAPIKey(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("not found id: " + id.String())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+func (r *PostgresAPIKeyRepo) DeleteAPIKeysByUser(ctx context.Context, userID uuid.UUID) (int, error) {
+	result, err := r.txManager.exec(ctx, `DELETE FROM api_keys WHERE user_id = $1`, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := result.RowsAffected()
+	return int(deleted), err
+}
+
+func execInsertAPIKey(ctx context.Context, q querier, key *domain.APIKey) error {
+	_, err := q.ExecContext(ctx,
+		`INSERT INTO api_keys (`+apiKeyColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		key.ID,
+		key.UserID,
+		key.Name,
+		key.Hint,
+		key.Hash,
+		pq.Array(key.Scopes),
+		key.CreatedAt,
+		nullTime(key.LastUsedAt),
+	)
+	return err
+}
+
+func scanAPIKey(row scanner) (*domain.APIKey, error) {
+	key := &domain.APIKey{}
+	var lastUsedAt sql.NullTime
+	if err := row.Scan(
+		&key.ID,
+		&key.UserID,
+		&key.Name,
+		&key.Hint,
+		&key.Hash,
+		pq.Array(&key.Scopes),
+		&key.CreatedAt,
+		&lastUsedAt,
+	); err != nil {
+		return nil, err
+	}
+	key.LastUsedAt = lastUsedAt.Time
+
+	return key, nil
+}
+
+// nullTime stores the zero time as NULL.
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
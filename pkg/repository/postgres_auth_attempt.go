@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+)
+
+const authAttemptColumns = `id, state, verifier, return_to, expires_at`
+
+type PostgresAuthAttemptStore struct {
+	txManager *PostgresTxManager
+}
+
+func NewPostgresAuthAttemptStore(txManager *PostgresTxManager) *PostgresAuthAttemptStore {
+	return &PostgresAuthAttemptStore{
+		txManager: txManager,
+	}
+}
+
+func (s *PostgresAuthAttemptStore) Put(ctx context.Context, attempt *domain.AuthAttempt) error {
+	_, err := s.txManager.exec(ctx,
+		`INSERT INTO auth_attempts (`+authAttemptColumns+`) VALUES ($1, $2, $3, $4, $5)`,
+		attempt.ID,
+		attempt.State,
+		attempt.Verifier,
+		attempt.ReturnTo,
+		attempt.ExpiresAt,
+	)
+	return err
+}
+
+// Take deletes the attempt in the same statement that reads it, so two
+// replicas racing for one callback cannot both get it.
+func (s *PostgresAuthAttemptStore) Take(ctx context.Context, id string) (*domain.AuthAttempt, bool, error) {
+	row := s.txManager.queryRow(ctx,
+		`DELETE FROM auth_attempts WHERE id = $1 RETURNING `+authAttemptColumns,
+		id,
+	)
+
+	attempt := &domain.AuthAttempt{}
+	err := row.Scan(
+		&attempt.ID,
+		&attempt.State,
+		&attempt.Verifier,
+		&attempt.ReturnTo,
+		&attempt.ExpiresAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return attempt, true, nil
+}
+
+func (s *PostgresAuthAttemptStore) DeleteExpired(ctx context.Context, before time.Time) (int, error) {
+	result, err := s.txManager.exec(ctx, `DELETE FROM auth_attempts WHERE expires_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := result.RowsAffected()
+	return int(deleted), err
+}
@@ -0,0 +1,448 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+)
+
+const (
+	keywordNodeColumns = `id, user_id, notion_page_id, keyword, stale`
+	keywordEdgeColumns = `id, user_id, keyword1, keyword2`
+)
+
+type PostgresMindMapRepo struct {
+	txManager *PostgresTxManager
+}
+
+func NewPostgresMindMapRepo(txManager *PostgresTxManager) *PostgresMindMapRepo {
+	return &PostgresMindMapRepo{
+		txManager: txManager,
+	}
+}
+
+func (r *PostgresMindMapRepo) BeginTransaction(ctx context.Context) (context.Context, error) {
+	return r.txManager.BeginTransaction(ctx)
+}
+
+func (r *PostgresMindMapRepo) Commit(ctx context.Context) error {
+	return r.txManager.Commit(ctx)
+}
+
+func (r *PostgresMindMapRepo) Abort(ctx context.Context) {
+	r.txManager.Abort(ctx)
+}
+
+func (r *PostgresMindMapRepo) CreateKeywordNode(
+	ctx context.Context,
+	node *domain.KeywordNode,
+) (*domain.KeywordNode, error) {
+	err := r.txManager.run(ctx, func(q querier) error {
+		return insertKeywordNode(ctx, q, node)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	copied := *node
+	return &copied, nil
+}
+
+func (r *PostgresMindMapRepo) CreateBulkKeywordNodes(
+	ctx context.Context,
+	bulks ...*domain.KeywordNode,
+) ([]*domain.KeywordNode, error) {
+	nodeCopies := make([]*domain.KeywordNode, 0, len(bulks))
+	err := r.txManager.run(ctx, func(q querier) error {
+		for _, node := range bulks {
+			if err := insertKeywordNode(ctx, q, node); err != nil {
+				return err
+			}
+			copied := *node
+			nodeCopies = append(nodeCopies, &copied)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return nodeCopies, nil
+}
+
+func (r *PostgresMindMapRepo) FindKeywordNodeByID(
+	ctx context.Context,
+	id uuid.UUID,
+) (*domain.KeywordNode, error) {
+	row := r.txManager.queryRow(ctx,
+		`SELECT `+keywordNodeColumns+` FROM keyword_nodes WHERE id = $1`,
+		id,
+	)
+
+	node, err := scanKeywordNode(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("not found id: " + id.String())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return node, nil
+}
+
+func (r *PostgresMindMapRepo) ListKeywordNodeByUser(
+	ctx context.Context,
+	userID uuid.UUID,
+) ([]*domain.KeywordNode, error) {
+	return r.listKeywordNodes(ctx,
+		`SELECT `+keywordNodeColumns+` FROM keyword_nodes WHERE user_id = $1`,
+		userID,
+	)
+}
+
+func (r *PostgresMindMapRepo) ListKeywordNodeByNotionPage(
+	ctx context.Context,
+	notionID uuid.UUID,
+) ([]*domain.KeywordNode, error) {
+	return r.listKeywordNodes(ctx,
+		`SELECT `+keywordNodeColumns+` FROM keyword_nodes WHERE notion_page_id = $1`,
+		notionID,
+	)
+}
+
+func (r *PostgresMindMapRepo) ListKeywordNodes(ctx context.Context) ([]*domain.KeywordNode, error) {
+	return r.listKeywordNodes(ctx, `SELECT `+keywordNodeColumns+` FROM keyword_nodes`)
+}
+
+// RestoreBulkKeywordNodes stores the nodes under their own ids.
+func (r *PostgresMindMapRepo) RestoreBulkKeywordNodes(
+	ctx context.Context,
+	bulks ...*domain.KeywordNode,
+) error {
+	return r.txManager.run(ctx, func(q querier) error {
+		for _, node := range bulks {
+			if err := putKeywordNode(ctx, q, node); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// MarkKeywordNodesStale flags the nodes extracted from the given notion pages
+// and returns how many were not flagged yet.
+func (r *PostgresMindMapRepo) MarkKeywordNodesStale(ctx context.Context, notionIDs []uuid.UUID) (int, error) {
+	result, err := r.txManager.exec(ctx,
+		`UPDATE keyword_nodes SET stale = TRUE WHERE notion_page_id = ANY($1::uuid[]) AND NOT stale`,
+		uuidArray(notionIDs),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	marked, err := result.RowsAffected()
+	return int(marked), err
+}
+
+func (r *PostgresMindMapRepo) DeleteKeywordNodeByID(
+	ctx context.Context,
+	id uuid.UUID,
+) (*domain.KeywordNode, error) {
+	row := r.txManager.queryRow(ctx,
+		`DELETE FROM keyword_nodes WHERE id = $1 RETURNING `+keywordNodeColumns,
+		id,
+	)
+
+	deleted, err := scanKeywordNode(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("not found id: " + id.String())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+func (r *PostgresMindMapRepo) DeleteBulkKeywordNodes(
+	ctx context.Context,
+	ids []uuid.UUID,
+) ([]*domain.KeywordNode, error) {
+	deleted := make([]*domain.KeywordNode, 0, len(ids))
+	err := r.txManager.run(ctx, func(q querier) error {
+		rows, err := q.QueryContext(ctx,
+			`DELETE FROM keyword_nodes WHERE id = ANY($1::uuid[]) RETURNING `+keywordNodeColumns,
+			uuidArray(ids),
+		)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			node, err := scanKeywordNode(rows)
+			if err != nil {
+				return err
+			}
+			deleted = append(deleted, node)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		return checkAllDeleted(ids, domain.ExtractIDFromBulkNodes(deleted))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+func (r *PostgresMindMapRepo) CreateKeywordEdge(
+	ctx context.Context,
+	edge *domain.KeywordEdge,
+) (*domain.KeywordEdge, error) {
+	err := r.txManager.run(ctx, func(q querier) error {
+		return insertKeywordEdge(ctx, q, edge)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	copied := *edge
+	return &copied, nil
+}
+
+func (r *PostgresMindMapRepo) CreateBulkKeywordEdges(
+	ctx context.Context,
+	bulks ...*domain.KeywordEdge,
+) ([]*domain.KeywordEdge, error) {
+	edgeCopies := make([]*domain.KeywordEdge, 0, len(bulks))
+	err := r.txManager.run(ctx, func(q querier) error {
+		for _, edge := range bulks {
+			if err := insertKeywordEdge(ctx, q, edge); err != nil {
+				return err
+			}
+			copied := *edge
+			edgeCopies = append(edgeCopies, &copied)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return edgeCopies, nil
+}
+
+func (r *PostgresMindMapRepo) ListKeywordEdgeByUser(
+	ctx context.Context,
+	userID uuid.UUID,
+) ([]*domain.KeywordEdge, error) {
+	return r.listKeywordEdges(ctx,
+		`SELECT `+keywordEdgeColumns+` FROM keyword_edges WHERE user_id = $1`,
+		userID,
+	)
+}
+
+func (r *PostgresMindMapRepo) ListKeywordEdges(ctx context.Context) ([]*domain.KeywordEdge, error) {
+	return r.listKeywordEdges(ctx, `SELECT `+keywordEdgeColumns+` FROM keyword_edges`)
+}
+
+// RestoreBulkKeywordEdges stores the edges under their own ids.
+func (r *PostgresMindMapRepo) RestoreBulkKeywordEdges(
+	ctx context.Context,
+	bulks ...*domain.KeywordEdge,
+) error {
+	return r.txManager.run(ctx, func(q querier) error {
+		for _, edge := range bulks {
+			if err := putKeywordEdge(ctx, q, edge); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *PostgresMindMapRepo) DeleteKeywordEdgeByID(
+	ctx context.Context,
+	id uuid.UUID,
+) (*domain.KeywordEdge, error) {
+	row := r.txManager.queryRow(ctx,
+		`DELETE FROM keyword_edges WHERE id = $1 RETURNING `+keywordEdgeColumns,
+		id,
+	)
+
+	deleted, err := scanKeywordEdge(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("not found id: " + id.String())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+func (r *PostgresMindMapRepo) DeleteBulkKeywordEdges(
+	ctx context.Context,
+	ids []uuid.UUID,
+) ([]*domain.KeywordEdge, error) {
+	deleted := make([]*domain.KeywordEdge, 0, len(ids))
+	err := r.txManager.run(ctx, func(q querier) error {
+		rows, err := q.QueryContext(ctx,
+			`DELETE FROM keyword_edges WHERE id = ANY($1::uuid[]) RETURNING `+keywordEdgeColumns,
+			uuidArray(ids),
+		)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			edge, err := scanKeywordEdge(rows)
+			if err != nil {
+				return err
+			}
+			deleted = append(deleted, edge)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		return checkAllDeleted(ids, domain.ExtractIDFromBulkEdges(deleted))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+func (r *PostgresMindMapRepo) listKeywordNodes(
+	ctx context.Context,
+	query string,
+	args ...any,
+) ([]*domain.KeywordNode, error) {
+	rows, err := r.txManager.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	nodes := make([]*domain.KeywordNode, 0)
+	for rows.Next() {
+		node, err := scanKeywordNode(rows)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
+
+func (r *PostgresMindMapRepo) listKeywordEdges(
+	ctx context.Context,
+	query string,
+	args ...any,
+) ([]*domain.KeywordEdge, error) {
+	rows, err := r.txManager.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	edges := make([]*domain.KeywordEdge, 0)
+	for rows.Next() {
+		edge, err := scanKeywordEdge(rows)
+		if err != nil {
+			return nil, err
+		}
+		edges = append(edges, edge)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return edges, nil
+}
+
+func insertKeywordNode(ctx context.Context, q querier, node *domain.KeywordNode) error {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return err
+	}
+	node.ID = id
+
+	return putKeywordNode(ctx, q, node)
+}
+
+func putKeywordNode(ctx context.Context, q querier, node *domain.KeywordNode) error {
+	_, err := q.ExecContext(ctx,
+		`INSERT INTO keyword_nodes (`+keywordNodeColumns+`) VALUES ($1, $2, $3, $4, $5)`,
+		node.ID, node.UserID, node.NotionPageID, node.Keyword, node.Stale,
+	)
+	return err
+}
+
+func insertKeywordEdge(ctx context.Context, q querier, edge *domain.KeywordEdge) error {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return err
+	}
+	edge.ID = id
+
+	return putKeywordEdge(ctx, q, edge)
+}
+
+func putKeywordEdge(ctx context.Context, q querier, edge *domain.KeywordEdge) error {
+	_, err := q.ExecContext(ctx,
+		`INSERT INTO keyword_edges (`+keywordEdgeColumns+`) VALUES ($1, $2, $3, $4)`,
+		edge.ID, edge.UserID, edge.Keyword1, edge.Keyword2,
+	)
+	return err
+}
+
+func scanKeywordNode(row scanner) (*domain.KeywordNode, error) {
+	node := &domain.KeywordNode{}
+	if err := row.Scan(&node.ID, &node.UserID, &node.NotionPageID, &node.Keyword, &node.Stale); err != nil {
+		return nil, err
+	}
+
+	return node, nil
+}
+
+func scanKeywordEdge(row scanner) (*domain.KeywordEdge, error) {
+	edge := &domain.KeywordEdge{}
+	if err := row.Scan(&edge.ID, &edge.UserID, &edge.Keyword1, &edge.Keyword2); err != nil {
+		return nil, err
+	}
+
+	return edge, nil
+}
+
+// checkAllDeleted reports the first requested id that was not deleted, so bulk
+// deletes fail the same way as the memory repository does.
+func checkAllDeleted(requested, deleted []uuid.UUID) error {
+	found := make(map[uuid.UUID]struct{}, len(deleted))
+	for _, id := range deleted {
+		found[id] = struct{}{}
+	}
+
+	for _, id := range requested {
+		if _, ok := found[id]; !ok {
+			return errors.New("not found id: " + id.String())
+		}
+	}
+
+	return nil
+}
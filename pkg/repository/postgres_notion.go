@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+)
+
+const notionPageColumns = `id, user_id, content, notion_url, notion_page_id, summary,
+	last_edited_at, last_synced_at, sync_status, sync_error, markdown, outline`
+
+type PostgresNotionPageRepo struct {
+	txManager *PostgresTxManager
+}
+
+func NewPostgresNotionPageRepo(txManager *PostgresTxManager) *PostgresNotionPageRepo {
+	return &PostgresNotionPageRepo{
+		txManager: txManager,
+	}
+}
+
+func (r *PostgresNotionPageRepo) BeginTransaction(ctx context.Context) (context.Context, error) {
+	return r.txManager.BeginTransaction(ctx)
+}
+
+func (r *PostgresNotionPageRepo) Commit(ctx context.Context) error {
+	return r.txManager.Commit(ctx)
+}
+
+func (r *PostgresNotionPageRepo) Abort(ctx context.Context) {
+	r.txManager.Abort(ctx)
+}
+
+func (r *PostgresNotionPageRepo) CreateNotionPage(
+	ctx context.Context,
+	page *domain.NotionPage,
+) (*domain.NotionPage, error) {
+	err := r.txManager.run(ctx, func(q querier) error {
+		return r.insertNotionPage(ctx, q, page)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	copied := *page
+	return &copied, nil
+}
+
+func (r *PostgresNotionPageRepo) CreateNotionPages(
+	ctx context.Context,
+	pages []*domain.NotionPage,
+) ([]*domain.NotionPage, error) {
+	createdPages := make([]*domain.NotionPage, 0, len(pages))
+	err := r.txManager.run(ctx, func(q querier) error {
+		for _, page := range pages {
+			if page == nil {
+				continue
+			}
+			if err := r.insertNotionPage(ctx, q, page); err != nil {
+				return err
+			}
+			copied := *page
+			createdPages = append(createdPages, &copied)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return createdPages, nil
+}
+
+func (r *PostgresNotionPageRepo) FindAllNotionPagesByUser(
+	ctx context.Context,
+	userID uuid.UUID,
+) ([]*domain.NotionPage, error) {
+	return r.listNotionPages(ctx,
+		`SELECT `+notionPageColumns+` FROM notion_pages WHERE user_id = $1`,
+		userID,
+	)
+}
+
+func (r *PostgresNotionPageRepo) FindNotionPageByID(
+	ctx context.Context,
+	id uuid.UUID,
+) (*domain.NotionPage, error) {
+	row := r.txManager.queryRow(ctx,
+		`SELECT `+notionPageColumns+` FROM notion_pages WHERE id = $1`,
+		id,
+	)
+
+	page, err := scanNotionPage(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("not found id: " + id.String())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+func (r *PostgresNotionPageRepo) ListNotionPages(ctx context.Context) ([]*domain.NotionPage, error) {
+	return r.listNotionPages(ctx, `SELECT `+notionPageColumns+` FROM notion_pages`)
+}
+
+func (r *PostgresNotionPageRepo) UpdateNotionPage(
+	ctx context.Context,
+	page *domain.NotionPage,
+) (*domain.NotionPage, error) {
+	outline, err := outlineJSON(page)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.txManager.exec(ctx,
+		`UPDATE notion_pages SET
+			user_id = $2,
+			content = $3,
+			notion_url = $4,
+			notion_page_id = $5,
+			summary = $6,
+			last_edited_at = $7,
+			last_synced_at = $8,
+			sync_status = $9,
+			sync_error = $10,
+			markdown = $11,
+			outline = $12
+		WHERE id = $1`,
+		page.ID, page.UserID, page.Content, page.NotionURL, page.NotionPageID, page.Summary,
+		nullTime(page.LastEditedAt), nullTime(page.LastSyncedAt), page.SyncStatus, page.SyncError,
+		page.Markdown, outline,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireAffected(result, page.ID); err != nil {
+		return nil, err
+	}
+
+	copied := *page
+	return &copied, nil
+}
+
+// RestoreNotionPages inserts the pages under their own ids.
+func (r *PostgresNotionPageRepo) RestoreNotionPages(ctx context.Context, pages ...*domain.NotionPage) error {
+	return r.txManager.run(ctx, func(q querier) error {
+		for _, page := range pages {
+			if err := r.execInsertNotionPage(ctx, q, page); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *PostgresNotionPageRepo) DeleteNotionPageByID(
+	ctx context.Context,
+	id uuid.UUID,
+) (*domain.NotionPage, error) {
+	row := r.txManager.queryRow(ctx,
+		`DELETE FROM notion_pages WHERE id = $1 RETURNING `+notionPageColumns,
+		id,
+	)
+
+	deleted, err := scanNotionPage(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("not found id: " + id.String())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+func (r *PostgresNotionPageRepo) listNotionPages(
+	ctx context.Context,
+	query string,
+	args ...any,
+) ([]*domain.NotionPage, error) {
+	rows, err := r.txManager.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pages := make([]*domain.NotionPage, 0)
+	for rows.Next() {
+		page, err := scanNotionPage(rows)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, page)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return pages, nil
+}
+
+func (r *PostgresNotionPageRepo) insertNotionPage(
+	ctx context.Context,
+	q querier,
+	page *domain.NotionPage,
+) error {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return err
+	}
+	page.ID = id
+
+	return r.execInsertNotionPage(ctx, q, page)
+}
+
+func (r *PostgresNotionPageRepo) execInsertNotionPage(
+	ctx context.Context,
+	q querier,
+	page *domain.NotionPage,
+) error {
+	outline, err := outlineJSON(page)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.ExecContext(ctx,
+		`INSERT INTO notion_pages (`+notionPageColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+		page.ID, page.UserID, page.Content, page.NotionURL, page.NotionPageID, page.Summary,
+		nullTime(page.LastEditedAt), nullTime(page.LastSyncedAt), page.SyncStatus, page.SyncError,
+		page.Markdown, outline,
+	)
+	return err
+}
+
+func scanNotionPage(row scanner) (*domain.NotionPage, error) {
+	page := &domain.NotionPage{}
+	var lastEditedAt, lastSyncedAt sql.NullTime
+	var outline []byte
+	if err := row.Scan(
+		&page.ID,
+		&page.UserID,
+		&page.Content,
+		&page.NotionURL,
+		&page.NotionPageID,
+		&page.Summary,
+		&lastEditedAt,
+		&lastSyncedAt,
+		&page.SyncStatus,
+		&page.SyncError,
+		&page.Markdown,
+		&outline,
+	); err != nil {
+		return nil, err
+	}
+	page.LastEditedAt = lastEditedAt.Time
+	page.LastSyncedAt = lastSyncedAt.Time
+	if len(outline) > 0 {
+		if err := json.Unmarshal(outline, &page.Outline); err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
+
+// outlineJSON stores a missing outline as NULL.
+func outlineJSON(page *domain.NotionPage) ([]byte, error) {
+	if len(page.Outline) == 0 {
+		return nil, nil
+	}
+
+	return json.Marshal(page.Outline)
+}
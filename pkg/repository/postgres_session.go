@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+)
+
+const sessionColumns = `id, user_id, notion_user_id, token, user_agent, created_at, last_seen_at`
+
+type PostgresSessionStore struct {
+	txManager *PostgresTxManager
+}
+
+func NewPostgresSessionStore(txManager *PostgresTxManager) *PostgresSessionStore {
+	return &PostgresSessionStore{
+		txManager: txManager,
+	}
+}
+
+func (s *PostgresSessionStore) Get(ctx context.Context, id string) (*domain.Session, bool, error) {
+	row := s.txManager.queryRow(ctx,
+		`SELECT `+sessionColumns+` FROM sessions WHERE id = $1`,
+		id,
+	)
+
+	session, err := scanSession(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return session, true, nil
+}
+
+func (s *PostgresSessionStore) Set(ctx context.Context, session *domain.Session) error {
+	token, err := json.Marshal(session.Token)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.txManager.exec(ctx,
+		`INSERT INTO sessions (`+sessionColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (id) DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			notion_user_id = EXCLUDED.notion_user_id,
+			token = EXCLUDED.token,
+			user_agent = EXCLUDED.user_agent,
+			created_at = EXCLUDED.created_at,
+			last_seen_at = EXCLUDED.last_seen_at`,
+		session.ID,
+		session.UserID,
+		session.NotionUserID,
+		token,
+		session.UserAgent,
+		session.CreatedAt,
+		session.LastSeenAt,
+	)
+	return err
+}
+
+func (s *PostgresSessionStore) Delete(ctx context.Context, id string) error {
+	_, err := s.txManager.exec(ctx, `DELETE FROM sessions WHERE id = $1`, id)
+	return err
+}
+
+func (s *PostgresSessionStore) Touch(ctx context.Context, id string, at time.Time) error {
+	_, err := s.txManager.exec(ctx,
+		`UPDATE sessions SET last_seen_at = $2 WHERE id = $1`,
+		id, at,
+	)
+	return err
+}
+
+func (s *PostgresSessionStore) ListByUser(
+	ctx context.Context,
+	userID uuid.UUID,
+) ([]*domain.Session, error) {
+	rows, err := s.txManager.query(ctx,
+		`SELECT `+sessionColumns+` FROM sessions WHERE user_id = $1 ORDER BY created_at`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]*domain.Session, 0)
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (s *PostgresSessionStore) DeleteByUser(ctx context.Context, userID uuid.UUID) (int, error) {
+	result, err := s.txManager.exec(ctx, `DELETE FROM sessions WHERE user_id = $1`, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := result.RowsAffected()
+	return int(deleted), err
+}
+
+func (s *PostgresSessionStore) DeleteExpired(
+	ctx context.Context,
+	createdBefore, idleBefore time.Time,
+) (int, error) {
+	result, err := s.txManager.exec(ctx,
+		`DELETE FROM sessions WHERE created_at < $1 OR last_seen_at < $2`,
+		createdBefore, idleBefore,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := result.RowsAffected()
+	return int(deleted), err
+}
+
+func scanSession(row scanner) (*domain.Session, error) {
+	session := &domain.Session{}
+	var token []byte
+	if err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.NotionUserID,
+		&token,
+		&session.UserAgent,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if len(token) > 0 {
+		if err := json.Unmarshal(token, &session.Token); err != nil {
+			return nil, err
+		}
+	}
+
+	return session, nil
+}
@@ -0,0 +1,357 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+)
+
+// openTestPostgres connects to the database of TEST_POSTGRES_DSN and migrates
+// it. The tests are skipped when it is not set. They only touch rows of users
+// they create, so the database may hold other data.
+func openTestPostgres(t *testing.T) *PostgresTxManager {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := migratePostgres(context.Background(), db, false); err != nil {
+		t.Fatal(err)
+	}
+
+	return NewPostgresTxManager(db)
+}
+
+// createTestPostgresUser creates a user whose rows are deleted when the test
+// ends.
+func createTestPostgresUser(t *testing.T, txManager *PostgresTxManager) *domain.User {
+	t.Helper()
+	ctx := context.Background()
+
+	user, err := NewPostgresUserRepo(txManager).CreateUser(ctx, &domain.User{
+		Nickname:     "alice",
+		NotionUserID: uuid.New(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		for _, table := range []string{"keyword_edges", "keyword_nodes", "notion_pages", "sessions", "api_keys", "users"} {
+			column := "user_id"
+			if table == "users" {
+				column = "id"
+			}
+			if _, err := txManager.db.ExecContext(ctx, `DELETE FROM `+table+` WHERE `+column+` = $1`, user.ID); err != nil {
+				t.Errorf("fail to clean up %s: %v", table, err)
+			}
+		}
+	})
+
+	return user
+}
+
+func TestPostgresUserRepo(t *testing.T) {
+	ctx := context.Background()
+	txManager := openTestPostgres(t)
+	repo := NewPostgresUserRepo(txManager)
+	user := createTestPostgresUser(t, txManager)
+
+	found, err := repo.FindUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Nickname != "alice" || found.NotionUserID != user.NotionUserID {
+		t.Fatalf("found user %+v, want %+v", found, user)
+	}
+
+	found.Nickname = "bob"
+	found.NotionBotID = "bot-" + user.ID.String()
+	if _, err := repo.UpdateUser(ctx, found); err != nil {
+		t.Fatal(err)
+	}
+	byBot, err := repo.FindUserByNotionBotID(ctx, found.NotionBotID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if byBot.ID != user.ID || byBot.Nickname != "bob" {
+		t.Fatalf("user by bot id %+v, want the updated user", byBot)
+	}
+
+	if _, err := repo.UpdateUser(ctx, &domain.User{ID: uuid.New()}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("update of a missing user = %v, want %v", err, ErrNotFound)
+	}
+	if _, err := repo.FindUserByNotionUserID(ctx, uuid.New()); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("find of a missing notion user = %v, want %v", err, ErrNotFound)
+	}
+
+	if _, err := repo.DeleteUserByID(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.FindUserByID(ctx, user.ID); err == nil {
+		t.Fatal("deleted user is still found")
+	}
+}
+
+func TestPostgresNotionPageRepo(t *testing.T) {
+	ctx := context.Background()
+	txManager := openTestPostgres(t)
+	repo := NewPostgresNotionPageRepo(txManager)
+	user := createTestPostgresUser(t, txManager)
+
+	synced := time.Now().UTC().Truncate(time.Second)
+	created, err := repo.CreateNotionPages(ctx, []*domain.NotionPage{
+		{UserID: user.ID, Content: "first", NotionPageID: uuid.New()},
+		nil,
+		{
+			UserID:       user.ID,
+			Content:      "second",
+			NotionPageID: uuid.New(),
+			LastSyncedAt: synced,
+			SyncStatus:   domain.NotionPageSynced,
+			Outline:      []*domain.NotionOutlineItem{{Level: 1, Text: "heading"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 2 {
+		t.Fatalf("created %d pages, want 2", len(created))
+	}
+
+	pages, err := repo.FindAllNotionPagesByUser(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 2 {
+		t.Fatalf("user has %d pages, want 2", len(pages))
+	}
+
+	found, err := repo.FindNotionPageByID(ctx, created[1].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found.LastSyncedAt.Equal(synced) || len(found.Outline) != 1 || found.Outline[0].Text != "heading" {
+		t.Fatalf("found page %+v, want the sync state it was created with", found)
+	}
+
+	found.Content = "edited"
+	if _, err := repo.UpdateNotionPage(ctx, found); err != nil {
+		t.Fatal(err)
+	}
+	if found, err = repo.FindNotionPageByID(ctx, found.ID); err != nil || found.Content != "edited" {
+		t.Fatalf("page after the update = %+v, %v", found, err)
+	}
+
+	missing := &domain.NotionPage{ID: uuid.New(), UserID: user.ID}
+	if _, err := repo.UpdateNotionPage(ctx, missing); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("update of a missing page = %v, want %v", err, ErrNotFound)
+	}
+
+	if _, err := repo.DeleteNotionPageByID(ctx, created[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.FindNotionPageByID(ctx, created[0].ID); err == nil {
+		t.Fatal("deleted page is still found")
+	}
+}
+
+func TestPostgresMindMapRepo(t *testing.T) {
+	ctx := context.Background()
+	txManager := openTestPostgres(t)
+	repo := NewPostgresMindMapRepo(txManager)
+	user := createTestPostgresUser(t, txManager)
+
+	pageID := uuid.New()
+	nodes, err := repo.CreateBulkKeywordNodes(ctx,
+		&domain.KeywordNode{UserID: user.ID, NotionPageID: pageID, Keyword: "go"},
+		&domain.KeywordNode{UserID: user.ID, NotionPageID: uuid.New(), Keyword: "postgres"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edges, err := repo.CreateBulkKeywordEdges(ctx, &domain.KeywordEdge{
+		UserID:   user.ID,
+		Keyword1: nodes[0].ID,
+		Keyword2: nodes[1].ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listed, err := repo.ListKeywordEdgeByUser(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 1 || listed[0].ID != edges[0].ID {
+		t.Fatalf("user has edges %+v, want %+v", listed, edges)
+	}
+
+	stale, err := repo.MarkKeywordNodesStale(ctx, []uuid.UUID{pageID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stale != 1 {
+		t.Fatalf("flagged %d keyword nodes, want 1", stale)
+	}
+	found, err := repo.FindKeywordNodeByID(ctx, nodes[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found.Stale || found.Keyword != "go" {
+		t.Fatalf("found node %+v, want the stale node of the page", found)
+	}
+
+	if _, err := repo.DeleteBulkKeywordNodes(ctx, []uuid.UUID{nodes[0].ID, uuid.New()}); err == nil {
+		t.Fatal("bulk delete with a missing node succeeded")
+	}
+	if _, err := repo.FindKeywordNodeByID(ctx, nodes[0].ID); err != nil {
+		t.Fatalf("failed bulk delete removed a node: %v", err)
+	}
+}
+
+func TestPostgresSessionStore(t *testing.T) {
+	ctx := context.Background()
+	txManager := openTestPostgres(t)
+	store := NewPostgresSessionStore(txManager)
+	user := createTestPostgresUser(t, txManager)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	session := &domain.Session{
+		ID:           uuid.NewString(),
+		UserID:       user.ID,
+		NotionUserID: user.NotionUserID,
+		Token:        &domain.Token{AccessToken: "access"},
+		CreatedAt:    now,
+		LastSeenAt:   now,
+	}
+	if err := store.Set(ctx, session); err != nil {
+		t.Fatal(err)
+	}
+
+	found, ok, err := store.Get(ctx, session.ID)
+	if err != nil || !ok {
+		t.Fatalf("get of a stored session = %v, %v", ok, err)
+	}
+	if found.UserID != user.ID || found.Token.AccessToken != "access" {
+		t.Fatalf("found session %+v, want %+v", found, session)
+	}
+
+	if err := store.Touch(ctx, session.ID, now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := store.ListByUser(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || !sessions[0].LastSeenAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("user has sessions %+v, want the touched session", sessions)
+	}
+
+	if err := store.Delete(ctx, session.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := store.Get(ctx, session.ID); err != nil || ok {
+		t.Fatalf("get of a deleted session = %v, %v, want not found", ok, err)
+	}
+}
+
+func TestPostgresAPIKeyRepo(t *testing.T) {
+	ctx := context.Background()
+	txManager := openTestPostgres(t)
+	repo := NewPostgresAPIKeyRepo(txManager)
+	user := createTestPostgresUser(t, txManager)
+
+	key, err := repo.CreateAPIKey(ctx, &domain.APIKey{
+		UserID:    user.ID,
+		Name:      "script",
+		Hash:      "hash-" + user.ID.String(),
+		Scopes:    []string{domain.ScopeRead},
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := repo.FindAPIKeyByHash(ctx, key.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.ID != key.ID || !found.HasScope(domain.ScopeRead) {
+		t.Fatalf("found api key %+v, want %+v", found, key)
+	}
+	if _, err := repo.FindAPIKeyByHash(ctx, "missing-"+user.ID.String()); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("find of a missing api key = %v, want %v", err, ErrNotFound)
+	}
+
+	deleted, err := repo.DeleteAPIKeysByUser(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 1 {
+		t.Fatalf("deleted %d api keys, want 1", deleted)
+	}
+}
+
+func TestPostgresUnitOfWorkRollback(t *testing.T) {
+	ctx := context.Background()
+	txManager := openTestPostgres(t)
+	users := NewPostgresUserRepo(txManager)
+	pages := NewPostgresNotionPageRepo(txManager)
+	uow := NewUnitOfWork(txManager)
+	user := createTestPostgresUser(t, txManager)
+
+	errRollback := errors.New("roll back")
+	err := uow.Do(ctx, func(ctx context.Context) error {
+		user.Nickname = "renamed"
+		if _, err := users.UpdateUser(ctx, user); err != nil {
+			return err
+		}
+		if _, err := pages.CreateNotionPage(ctx, &domain.NotionPage{UserID: user.ID, NotionPageID: uuid.New()}); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("unit of work = %v, want %v", err, errRollback)
+	}
+
+	found, err := users.FindUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Nickname != "alice" {
+		t.Fatalf("nickname = %q after the rollback, want %q", found.Nickname, "alice")
+	}
+	created, err := pages.FindAllNotionPagesByUser(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 0 {
+		t.Fatalf("user has %d pages after the rollback, want 0", len(created))
+	}
+
+	// A write in a read-only unit of work is refused by postgres.
+	err = uow.View(ctx, func(ctx context.Context) error {
+		_, err := pages.CreateNotionPage(ctx, &domain.NotionPage{UserID: user.ID, NotionPageID: uuid.New()})
+		return err
+	})
+	if err == nil {
+		t.Fatal("write in a read-only unit of work succeeded")
+	}
+}
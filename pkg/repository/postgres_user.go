@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+)
+
+const userColumns = `id, nickname, notion_user_id, access_token, refresh_token, role, disabled,
+	notion_disconnected, notion_bot_id`
+
+type PostgresUserRepo struct {
+	txManager *PostgresTxManager
+}
+
+func NewPostgresUserRepo(txManager *PostgresTxManager) *PostgresUserRepo {
+	return &PostgresUserRepo{
+		txManager: txManager,
+	}
+}
+
+func (r *PostgresUserRepo) BeginTransaction(ctx context.Context) (context.Context, error) {
+	return r.txManager.BeginTransaction(ctx)
+}
+
+func (r *PostgresUserRepo) Commit(ctx context.Context) error {
+	return r.txManager.Commit(ctx)
+}
+
+func (r *PostgresUserRepo) Abort(ctx context.Context) {
+	r.txManager.Abort(ctx)
+}
+
+func (r *PostgresUserRepo) CreateUser(ctx context.Context, user *domain.User) (*domain.User, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	user.ID = id
+
+	_, err = r.txManager.exec(ctx,
+		`INSERT INTO users (`+userColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		user.ID,
+		user.Nickname,
+		user.NotionUserID,
+		user.AccessToken,
+		user.RefreshToken,
+		userRole(user),
+		user.Disabled,
+		user.NotionDisconnected,
+		user.NotionBotID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	copied := *user
+	return &copied, nil
+}
+
+func (r *PostgresUserRepo) FindUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	row := r.txManager.queryRow(ctx,
+		`SELECT `+userColumns+` FROM users WHERE id = $1`,
+		id,
+	)
+
+	user, err := scanUser(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("not found id: " + id.String())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *PostgresUserRepo) FindUserByNotionUserID(
+	ctx context.Context,
+	notionUserID uuid.UUID,
+) (*domain.User, error) {
+	row := r.txManager.queryRow(ctx,
+		`SELECT `+userColumns+` FROM users WHERE notion_user_id = $1 LIMIT 1`,
+		notionUserID,
+	)
+
+	user, err := scanUser(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w notion user id: %s", ErrNotFound, notionUserID.String())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *PostgresUserRepo) FindUserByNotionBotID(ctx context.Context, botID string) (*domain.User, error) {
+	row := r.txManager.queryRow(ctx,
+		`SELECT `+userColumns+` FROM users WHERE notion_bot_id = $1 LIMIT 1`,
+		botID,
+	)
+
+	user, err := scanUser(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w notion bot id: %s", ErrNotFound, botID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *PostgresUserRepo) ListUsers(ctx context.Context) ([]*domain.User, error) {
+	rows, err := r.txManager.query(ctx, `SELECT `+userColumns+` FROM users`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]*domain.User, 0)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (r *PostgresUserRepo) UpdateUser(ctx context.Context, user *domain.User) (*domain.User, error) {
+	result, err := r.txManager.exec(ctx,
+		`UPDATE users SET
+			nickname = $2,
+			notion_user_id = $3,
+			access_token = $4,
+			refresh_token = $5,
+			role = $6,
+			disabled = $7,
+			notion_disconnected = $8,
+			notion_bot_id = $9
+		WHERE id = $1`,
+		user.ID,
+		user.Nickname,
+		user.NotionUserID,
+		user.AccessToken,
+		user.RefreshToken,
+		userRole(user),
+		user.Disabled,
+		user.NotionDisconnected,
+		user.NotionBotID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireAffected(result, user.ID); err != nil {
+		return nil, err
+	}
+
+	copied := *user
+	return &copied, nil
+}
+
+// RestoreUsers inserts the users under their own ids.
+func (r *PostgresUserRepo) RestoreUsers(ctx context.Context, users ...*domain.User) error {
+	return r.txManager.run(ctx, func(q querier) error {
+		for _, user := range users {
+			_, err := q.ExecContext(ctx,
+				`INSERT INTO users (`+userColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+				user.ID,
+				user.Nickname,
+				user.NotionUserID,
+				user.AccessToken,
+				user.RefreshToken,
+				userRole(user),
+				user.Disabled,
+				user.NotionDisconnected,
+				user.NotionBotID,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *PostgresUserRepo) DeleteUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	row := r.txManager.queryRow(ctx,
+		`DELETE FROM users WHERE id = $1 RETURNING `+userColumns,
+		id,
+	)
+
+	deleted, err := scanUser(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("not found id: " + id.String())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+func scanUser(row scanner) (*domain.User, error) {
+	user := &domain.User{}
+	if err := row.Scan(
+		&user.ID,
+		&user.Nickname,
+		&user.NotionUserID,
+		&user.AccessToken,
+		&user.RefreshToken,
+		&user.Role,
+		&user.Disabled,
+		&user.NotionDisconnected,
+		&user.NotionBotID,
+	); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// userRole fills in the role of users created before roles existed.
+func userRole(user *domain.User) string {
+	if user.Role == "" {
+		return domain.RoleUser
+	}
+
+	return user.Role
+}
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/config"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+)
+
+const (
+	DriverMemory   = "memory"
+	DriverPostgres = "postgres"
+	DriverBolt     = "bolt"
+)
+
+// ErrNotFound is wrapped by lookups whose callers need to tell a missing row
+// from a failure.
+var ErrNotFound = errors.New("not found")
+
+// Transactional is implemented by every repository. BeginTransaction returns a
+// context carrying the transaction, which the other calls then take part in;
+// Abort after a successful Commit is a no-op so it can always be deferred.
+type Transactional interface {
+	BeginTransaction(ctx context.Context) (context.Context, error)
+	Commit(ctx context.Context) error
+	Abort(ctx context.Context)
+}
+
+type UserRepository interface {
+	Transactional
+
+	CreateUser(ctx context.Context, user *domain.User) (*domain.User, error)
+	FindUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error)
+	FindUserByNotionUserID(ctx context.Context, notionUserID uuid.UUID) (*domain.User, error)
+	FindUserByNotionBotID(ctx context.Context, botID string) (*domain.User, error)
+	ListUsers(ctx context.Context) ([]*domain.User, error)
+	// UpdateUser fails with ErrNotFound when the user does not exist.
+	UpdateUser(ctx context.Context, user *domain.User) (*domain.User, error)
+	RestoreUsers(ctx context.Context, users ...*domain.User) error
+	DeleteUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error)
+}
+
+type NotionPageRepository interface {
+	Transactional
+
+	CreateNotionPage(ctx context.Context, page *domain.NotionPage) (*domain.NotionPage, error)
+	CreateNotionPages(ctx context.Context, pages []*domain.NotionPage) ([]*domain.NotionPage, error)
+	FindAllNotionPagesByUser(ctx context.Context, userID uuid.UUID) ([]*domain.NotionPage, error)
+	FindNotionPageByID(ctx context.Context, id uuid.UUID) (*domain.NotionPage, error)
+	ListNotionPages(ctx context.Context) ([]*domain.NotionPage, error)
+	// UpdateNotionPage fails with ErrNotFound when the page does not exist.
+	UpdateNotionPage(ctx context.Context, page *domain.NotionPage) (*domain.NotionPage, error)
+	RestoreNotionPages(ctx context.Context, pages ...*domain.NotionPage) error
+	DeleteNotionPageByID(ctx context.Context, id uuid.UUID) (*domain.NotionPage, error)
+}
+
+type MindMapRepository interface {
+	Transactional
+
+	CreateKeywordNode(ctx context.Context, node *domain.KeywordNode) (*domain.KeywordNode, error)
+	CreateBulkKeywordNodes(ctx context.Context, bulks ...*domain.KeywordNode) ([]*domain.KeywordNode, error)
+	FindKeywordNodeByID(ctx context.Context, id uuid.UUID) (*domain.KeywordNode, error)
+	ListKeywordNodeByUser(ctx context.Context, userID uuid.UUID) ([]*domain.KeywordNode, error)
+	ListKeywordNodeByNotionPage(ctx context.Context, notionID uuid.UUID) ([]*domain.KeywordNode, error)
+	ListKeywordNodes(ctx context.Context) ([]*domain.KeywordNode, error)
+	RestoreBulkKeywordNodes(ctx context.Context, bulks ...*domain.KeywordNode) error
+	MarkKeywordNodesStale(ctx context.Context, notionIDs []uuid.UUID) (int, error)
+	DeleteKeywordNodeByID(ctx context.Context, id uuid.UUID) (*domain.KeywordNode, error)
+	DeleteBulkKeywordNodes(ctx context.Context, ids []uuid.UUID) ([]*domain.KeywordNode, error)
+
+	CreateKeywordEdge(ctx context.Context, edge *domain.KeywordEdge) (*domain.KeywordEdge, error)
+	CreateBulkKeywordEdges(ctx context.Context, bulks ...*domain.KeywordEdge) ([]*domain.KeywordEdge, error)
+	ListKeywordEdgeByUser(ctx context.Context, userID uuid.UUID) ([]*domain.KeywordEdge, error)
+	ListKeywordEdges(ctx context.Context) ([]*domain.KeywordEdge, error)
+	RestoreBulkKeywordEdges(ctx context.Context, bulks ...*domain.KeywordEdge) error
+	DeleteKeywordEdgeByID(ctx context.Context, id uuid.UUID) (*domain.KeywordEdge, error)
+	DeleteBulkKeywordEdges(ctx context.Context, ids []uuid.UUID) ([]*domain.KeywordEdge, error)
+}
+
+type APIKeyRepository interface {
+	Transactional
+
+	CreateAPIKey(ctx context.Context, key *domain.APIKey) (*domain.APIKey, error)
+	FindAPIKeyByHash(ctx context.Context, hash string) (*domain.APIKey, error)
+	ListAPIKeysByUser(ctx context.Context, userID uuid.UUID) ([]*domain.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]*domain.APIKey, error)
+	RestoreAPIKeys(ctx context.Context, keys ...*domain.APIKey) error
+	TouchAPIKey(ctx context.Context, id uuid.UUID, at time.Time) error
+	DeleteAPIKeyByID(ctx context.Context, id uuid.UUID) (*domain.APIKey, error)
+	DeleteAPIKeysByUser(ctx context.Context, userID uuid.UUID) (int, error)
+}
+
+// SessionStore persists login sessions. Only the persistent backends provide
+// one; the api package keeps its own store for memory sessions.
+type SessionStore interface {
+	Get(ctx context.Context, id string) (*domain.Session, bool, error)
+	Set(ctx context.Context, session *domain.Session) error
+	Delete(ctx context.Context, id string) error
+	Touch(ctx context.Context, id string, at time.Time) error
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*domain.Session, error)
+	DeleteByUser(ctx context.Context, userID uuid.UUID) (int, error)
+	DeleteExpired(ctx context.Context, createdBefore, idleBefore time.Time) (int, error)
+}
+
+// AuthAttemptStore persists the pending notion sign in attempts, so a callback
+// may land on any replica.
+type AuthAttemptStore interface {
+	Put(ctx context.Context, attempt *domain.AuthAttempt) error
+	Take(ctx context.Context, id string) (*domain.AuthAttempt, bool, error)
+	DeleteExpired(ctx context.Context, before time.Time) (int, error)
+}
+
+var (
+	_ UserRepository       = (*MemoryUserRepo)(nil)
+	_ UserRepository       = (*PostgresUserRepo)(nil)
+	_ UserRepository       = (*BoltUserRepo)(nil)
+	_ NotionPageRepository = (*MemoryNotionPageRepo)(nil)
+	_ NotionPageRepository = (*PostgresNotionPageRepo)(nil)
+	_ NotionPageRepository = (*BoltNotionPageRepo)(nil)
+	_ MindMapRepository    = (*MemoryMindMapRepo)(nil)
+	_ MindMapRepository    = (*PostgresMindMapRepo)(nil)
+	_ MindMapRepository    = (*BoltMindMapRepo)(nil)
+	_ APIKeyRepository     = (*MemoryAPIKeyRepo)(nil)
+	_ APIKeyRepository     = (*PostgresAPIKeyRepo)(nil)
+	_ APIKeyRepository     = (*BoltAPIKeyRepo)(nil)
+	_ SessionStore         = (*PostgresSessionStore)(nil)
+	_ SessionStore         = (*BoltSessionStore)(nil)
+	_ AuthAttemptStore     = (*PostgresAuthAttemptStore)(nil)
+	_ AuthAttemptStore     = (*BoltAuthAttemptStore)(nil)
+)
+
+// Repositories groups the repositories of one storage backend.
+type Repositories struct {
+	User       UserRepository
+	NotionPage NotionPageRepository
+	MindMap    MindMapRepository
+	APIKey     APIKeyRepository
+	UnitOfWork UnitOfWork
+	// Sessions, AuthAttempts and Cipher are nil for the memory driver.
+	Sessions     SessionStore
+	AuthAttempts AuthAttemptStore
+	Cipher       *TokenCipher
+
+	closer io.Closer
+}
+
+// New opens the configured storage backend. The persistent backends store the
+// notion tokens encrypted with the keys of encryption.
+func New(
+	ctx context.Context,
+	storage *config.StorageConfig,
+	db *config.DBConfig,
+	encryption *config.EncryptionConfig,
+) (*Repositories, error) {
+	switch storage.Driver {
+	case "", DriverMemory:
+		txManager := NewMemoryTxManager()
+		return &Repositories{
+			User:       NewMemoryUserRepo(txManager),
+			NotionPage: NewMemoryNotionPageRepo(txManager),
+			MindMap:    NewMemoryMindMapRepo(txManager),
+			APIKey:     NewMemoryAPIKeyRepo(txManager),
+			UnitOfWork: NewUnitOfWork(txManager),
+		}, nil
+	case DriverPostgres:
+		cipher, err := newStorageCipher(encryption)
+		if err != nil {
+			return nil, err
+		}
+		conn, err := NewPostgresDB(db)
+		if err != nil {
+			return nil, err
+		}
+		if err := migratePostgres(ctx, conn, storage.RequireMigrated); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		txManager := NewPostgresTxManager(conn)
+		return &Repositories{
+			User:         newEncryptedUserRepo(NewPostgresUserRepo(txManager), cipher),
+			NotionPage:   NewPostgresNotionPageRepo(txManager),
+			MindMap:      NewPostgresMindMapRepo(txManager),
+			APIKey:       NewPostgresAPIKeyRepo(txManager),
+			UnitOfWork:   NewUnitOfWork(txManager),
+			Sessions:     newEncryptedSessionStore(NewPostgresSessionStore(txManager), cipher),
+			AuthAttempts: NewPostgresAuthAttemptStore(txManager),
+			Cipher:       cipher,
+			closer:       conn,
+		}, nil
+	case DriverBolt:
+		if storage.Path == "" {
+			return nil, errors.New("required storage path for bolt driver")
+		}
+		cipher, err := newStorageCipher(encryption)
+		if err != nil {
+			return nil, err
+		}
+		conn, err := NewBoltDB(storage.Path)
+		if err != nil {
+			return nil, err
+		}
+		store := newBoltStore(conn)
+		return &Repositories{
+			User:         newEncryptedUserRepo(newBoltUserRepo(store), cipher),
+			NotionPage:   newBoltNotionPageRepo(store),
+			MindMap:      newBoltMindMapRepo(store),
+			APIKey:       newBoltAPIKeyRepo(store),
+			UnitOfWork:   NewUnitOfWork(store),
+			Sessions:     newEncryptedSessionStore(newBoltSessionStore(store), cipher),
+			AuthAttempts: newBoltAuthAttemptStore(store),
+			Cipher:       cipher,
+			closer:       conn,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s", storage.Driver)
+	}
+}
+
+func newStorageCipher(encryption *config.EncryptionConfig) (*TokenCipher, error) {
+	cipher, err := NewTokenCipher(encryption)
+	if err != nil {
+		return nil, err
+	}
+	if !cipher.Enabled() {
+		if encryption == nil || !encryption.AllowPlaintextTokens {
+			return nil, errors.New("TOKEN_ENCRYPTION_KEY is required to store notion tokens, " +
+				"set ALLOW_PLAINTEXT_TOKENS=true to store them in plaintext instead")
+		}
+		log.Println("TOKEN_ENCRYPTION_KEY is not set, notion tokens are stored in plaintext")
+	}
+
+	return cipher, nil
+}
+
+func (r *Repositories) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+
+	return r.closer.Close()
+}
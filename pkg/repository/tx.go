@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	ErrTransactionConflict = errors.New("transaction conflict: the data was modified by another transaction")
+	ErrTransactionDone     = errors.New("transaction has already been committed or aborted")
+	ErrTransactionAborted  = errors.New("transaction was aborted by a nested scope")
+	ErrTransactionReadOnly = errors.New("transaction is read-only")
+)
+
+// transaction is the backend specific part of a transaction.
+type transaction interface {
+	commit() error
+	rollback()
+}
+
+// txState guards a transaction against being finished twice and remembers
+// when a nested scope asked for a rollback.
+type txState struct {
+	mu           sync.Mutex
+	tx           transaction
+	readOnly     bool
+	done         bool
+	rollbackOnly bool
+}
+
+func (s *txState) commit() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return ErrTransactionDone
+	}
+	s.done = true
+
+	if s.rollbackOnly {
+		s.tx.rollback()
+		return ErrTransactionAborted
+	}
+
+	return s.tx.commit()
+}
+
+func (s *txState) rollback() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return
+	}
+	s.done = true
+	s.tx.rollback()
+}
+
+func (s *txState) markRollbackOnly() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rollbackOnly = true
+}
+
+func (s *txState) active() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.done
+}
+
+type txContextKey struct{}
+
+type readOnlyContextKey struct{}
+
+// withReadOnly marks the transactions begun with ctx as read-only, or as
+// writable again for a nested scope.
+func withReadOnly(ctx context.Context, readOnly bool) context.Context {
+	return context.WithValue(ctx, readOnlyContextKey{}, readOnly)
+}
+
+// readOnlyFromContext reports whether a transaction begun with ctx only reads.
+func readOnlyFromContext(ctx context.Context) bool {
+	readOnly, _ := ctx.Value(readOnlyContextKey{}).(bool)
+	return readOnly
+}
+
+// txScope is the transaction handle carried by a context. A scope begun while
+// the context already holds a transaction of the same manager is nested: it
+// joins the outer transaction, its Commit is a no-op and its Abort makes the
+// outer Commit fail.
+type txScope struct {
+	manager any
+	state   *txState
+	nested  bool
+
+	mu       sync.Mutex
+	finished bool
+}
+
+// finish reports whether this is the first Commit or Abort of the scope.
+func (s *txScope) finish() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.finished {
+		return false
+	}
+	s.finished = true
+	return true
+}
+
+func scopeFromContext(ctx context.Context, manager any) (*txScope, bool) {
+	scope, ok := ctx.Value(txContextKey{}).(*txScope)
+	if !ok || scope.manager != manager {
+		return nil, false
+	}
+
+	return scope, true
+}
+
+func beginScope(
+	ctx context.Context,
+	manager any,
+	begin func(ctx context.Context) (transaction, error),
+) (context.Context, error) {
+	readOnly := readOnlyFromContext(ctx)
+	if outer, ok := scopeFromContext(ctx, manager); ok && outer.state.active() {
+		if outer.state.readOnly && !readOnly {
+			return ctx, ErrTransactionReadOnly
+		}
+		return context.WithValue(ctx, txContextKey{}, &txScope{
+			manager: manager,
+			state:   outer.state,
+			nested:  true,
+		}), nil
+	}
+
+	tx, err := begin(ctx)
+	if err != nil {
+		return ctx, err
+	}
+
+	return context.WithValue(ctx, txContextKey{}, &txScope{
+		manager: manager,
+		state:   &txState{tx: tx, readOnly: readOnly},
+	}), nil
+}
+
+func commitScope(ctx context.Context, manager any) error {
+	scope, ok := scopeFromContext(ctx, manager)
+	if !ok {
+		return nil
+	}
+	if scope.nested {
+		if !scope.finish() {
+			return ErrTransactionDone
+		}
+		return nil
+	}
+	scope.finish()
+
+	return scope.state.commit()
+}
+
+func abortScope(ctx context.Context, manager any) {
+	scope, ok := scopeFromContext(ctx, manager)
+	if !ok {
+		return
+	}
+
+	if !scope.finish() {
+		return
+	}
+	if scope.nested {
+		scope.state.markRollbackOnly()
+		return
+	}
+
+	scope.state.rollback()
+}
+
+// txFromContext returns the transaction of manager carried by ctx. ok is false
+// when there is none, and err is set when it has already been finished.
+func txFromContext[T transaction](ctx context.Context, manager any) (tx T, ok bool, err error) {
+	scope, ok := scopeFromContext(ctx, manager)
+	if !ok {
+		return tx, false, nil
+	}
+	if !scope.state.active() {
+		return tx, true, ErrTransactionDone
+	}
+
+	tx, ok = scope.state.tx.(T)
+	return tx, ok, nil
+}
@@ -0,0 +1,46 @@
+package repository
+
+import "context"
+
+// UnitOfWork runs a closure in one transaction spanning every repository of
+// the same storage backend. The repositories take part in it through the
+// context passed to the closure; a Do called inside another Do joins the outer
+// unit of work.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+	// View is Do for a closure which only reads, so backends can open a
+	// read-only transaction. A Do called inside it fails.
+	View(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type unitOfWork struct {
+	tx Transactional
+}
+
+func NewUnitOfWork(tx Transactional) UnitOfWork {
+	return &unitOfWork{
+		tx: tx,
+	}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return u.run(withReadOnly(ctx, false), fn)
+}
+
+func (u *unitOfWork) View(ctx context.Context, fn func(ctx context.Context) error) error {
+	return u.run(withReadOnly(ctx, true), fn)
+}
+
+func (u *unitOfWork) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	ctx, err := u.tx.BeginTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	defer u.tx.Abort(ctx)
+
+	if err := fn(ctx); err != nil {
+		return err
+	}
+
+	return u.tx.Commit(ctx)
+}
@@ -2,190 +2,131 @@ package repository
 
 import (
 	"context"
-	"errors"
-	"sync"
+	"fmt"
 
 	"github.com/google/uuid"
 
-	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/api"
 	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
 )
 
 type MemoryUserRepo struct {
-	mu     sync.RWMutex
-	users  map[uuid.UUID]*domain.User
-	caches map[uuid.UUID]*userCache
+	txManager *MemoryTxManager
+	users     memoryTable[domain.User]
 }
 
-type userCache struct {
-	mu               sync.RWMutex
-	deferedOperation []func()
-	users            map[uuid.UUID]*domain.User
-}
-
-func NewMemoryUserRepo() *MemoryUserRepo {
+func NewMemoryUserRepo(txManager *MemoryTxManager) *MemoryUserRepo {
 	return &MemoryUserRepo{
-		users:  make(map[uuid.UUID]*domain.User, 1024),
-		caches: make(map[uuid.UUID]*userCache, 0),
+		txManager: txManager,
+		users:     newMemoryTable[domain.User](txManager, "users"),
 	}
 }
 
-func (r *MemoryUserRepo) BeginTransaction(ctx context.Context) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return
-	}
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	users := make(map[uuid.UUID]*domain.User)
-	for k, v := range r.users {
-		users[k] = v
-	}
-
-	r.caches[requestID] = &userCache{
-		deferedOperation: make([]func(), 0),
-		users:            users,
-	}
+func (r *MemoryUserRepo) BeginTransaction(ctx context.Context) (context.Context, error) {
+	return r.txManager.BeginTransaction(ctx)
 }
 
-func (r *MemoryUserRepo) Commit(ctx context.Context) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return
-	}
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	cache, ok := r.caches[requestID]
-	if !ok {
-		return
-	}
-	for _, operation := range cache.deferedOperation {
-		operation()
-	}
+func (r *MemoryUserRepo) Commit(ctx context.Context) error {
+	return r.txManager.Commit(ctx)
 }
 
 func (r *MemoryUserRepo) Abort(ctx context.Context) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return
-	}
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	delete(r.caches, requestID)
+	r.txManager.Abort(ctx)
 }
 
 func (r *MemoryUserRepo) CreateUser(ctx context.Context, user *domain.User) (*domain.User, error) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return nil, errors.New("not found request id")
-	}
-
 	id, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
 	}
 	user.ID = id
-	copied := *user
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	cache, ok := r.caches[requestID]
-	if !ok {
-		r.users[id] = user
-		return &copied, nil
-	}
 
-	cache.mu.Lock()
-	defer cache.mu.Unlock()
-	cache.users[id] = user
-	cache.deferedOperation = append(cache.deferedOperation, func() {
-		r.users[id] = user
+	var created *domain.User
+	err = r.txManager.update(ctx, func(tx *memoryTx) error {
+		created = r.users.put(tx, id, user)
+		return nil
 	})
-	return &copied, nil
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
 }
 
 func (r *MemoryUserRepo) FindUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return nil, errors.New("not found request id")
-	}
+	return r.users.get(ctx, id)
+}
 
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	cache, ok := r.caches[requestID]
-	if !ok {
-		user, ok := r.users[id]
-		if !ok {
-			return nil, errors.New("not found id: " + id.String())
-		}
-		return user, nil
+func (r *MemoryUserRepo) FindUserByNotionUserID(
+	ctx context.Context,
+	notionUserID uuid.UUID,
+) (*domain.User, error) {
+	users, err := r.users.list(ctx, func(u *domain.User) bool {
+		return u.NotionUserID == notionUserID
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	cache.mu.RLock()
-	defer cache.mu.RUnlock()
-	user, ok := cache.users[id]
-	if !ok {
-		return nil, errors.New("not found id: " + id.String())
+	if len(users) == 0 {
+		return nil, fmt.Errorf("%w notion user id: %s", ErrNotFound, notionUserID.String())
 	}
-	return user, nil
+
+	return users[0], nil
 }
 
-func (r *MemoryUserRepo) UpdateUser(ctx context.Context, user *domain.User) (*domain.User, error) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return nil, errors.New("not found request id")
+func (r *MemoryUserRepo) FindUserByNotionBotID(ctx context.Context, botID string) (*domain.User, error) {
+	users, err := r.users.list(ctx, func(u *domain.User) bool {
+		return u.NotionBotID == botID
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	copied := *user
-
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	cache, ok := r.caches[requestID]
-	if !ok {
-		r.users[user.ID] = user
-		return &copied, nil
+	if len(users) == 0 {
+		return nil, fmt.Errorf("%w notion bot id: %s", ErrNotFound, botID)
 	}
 
-	cache.mu.Lock()
-	defer cache.mu.Unlock()
-	cache.users[user.ID] = user
-	cache.deferedOperation = append(cache.deferedOperation, func() {
-		r.users[user.ID] = user
+	return users[0], nil
+}
+
+func (r *MemoryUserRepo) ListUsers(ctx context.Context) ([]*domain.User, error) {
+	return r.users.list(ctx, func(*domain.User) bool {
+		return true
 	})
-	return &copied, nil
 }
 
-func (r *MemoryUserRepo) DeleteUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
-	requestID, ok := ctx.Value(api.RequestIDKey{}).(uuid.UUID)
-	if !ok {
-		return nil, errors.New("not found request id")
+func (r *MemoryUserRepo) UpdateUser(ctx context.Context, user *domain.User) (*domain.User, error) {
+	var updated *domain.User
+	err := r.txManager.update(ctx, func(tx *memoryTx) error {
+		var err error
+		updated, err = r.users.replace(tx, user.ID, user)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	cache, ok := r.caches[requestID]
-	if !ok {
-		deleted, ok := r.users[id]
-		if !ok {
-			return nil, errors.New("not found id: " + id.String())
+	return updated, nil
+}
+
+// RestoreUsers stores the users under their own ids.
+func (r *MemoryUserRepo) RestoreUsers(ctx context.Context, users ...*domain.User) error {
+	return r.txManager.update(ctx, func(tx *memoryTx) error {
+		for _, user := range users {
+			r.users.put(tx, user.ID, user)
 		}
-		delete(r.users, id)
-		return deleted, nil
-	}
+		return nil
+	})
+}
 
-	cache.mu.Lock()
-	defer cache.mu.Unlock()
-	deleted, ok := cache.users[id]
-	if !ok {
-		return nil, errors.New("not found id: " + id.String())
-	}
-	delete(cache.users, id)
-	cache.deferedOperation = append(cache.deferedOperation, func() {
-		delete(r.users, id)
+func (r *MemoryUserRepo) DeleteUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	var deleted *domain.User
+	err := r.txManager.update(ctx, func(tx *memoryTx) error {
+		var err error
+		deleted, err = r.users.delete(tx, id)
+		return err
 	})
+	if err != nil {
+		return nil, err
+	}
+
 	return deleted, nil
 }
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/repository"
+)
+
+// apiKeyTouchInterval keeps the last used timestamp from being written on
+// every request.
+const apiKeyTouchInterval = time.Minute
+
+var ErrInvalidAPIKeyScope = errors.New("api key scopes must be read and/or write")
+
+type APIKeyService struct {
+	repo     repository.APIKeyRepository
+	userRepo repository.UserRepository
+	uow      repository.UnitOfWork
+}
+
+func NewAPIKeyService(
+	repo repository.APIKeyRepository,
+	userRepo repository.UserRepository,
+	uow repository.UnitOfWork,
+) *APIKeyService {
+	return &APIKeyService{
+		repo:     repo,
+		userRepo: userRepo,
+		uow:      uow,
+	}
+}
+
+// CreateAPIKey returns a new key of the user and its record. The key is not
+// stored and cannot be shown again.
+func (s *APIKeyService) CreateAPIKey(
+	ctx context.Context,
+	userID uuid.UUID,
+	name string,
+	scopes []string,
+) (string, *domain.APIKey, error) {
+	if len(scopes) == 0 {
+		return "", nil, ErrInvalidAPIKeyScope
+	}
+	for _, scope := range scopes {
+		if !domain.ValidScope(scope) {
+			return "", nil, ErrInvalidAPIKeyScope
+		}
+	}
+	scopes = slices.Compact(slices.Sorted(slices.Values(scopes)))
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, err
+	}
+	secret := base64.RawURLEncoding.EncodeToString(buf)
+	key := domain.APIKeyPrefix + secret
+
+	created, err := s.repo.CreateAPIKey(ctx, &domain.APIKey{
+		UserID:    userID,
+		Name:      name,
+		Hint:      domain.APIKeyPrefix + secret[:6],
+		Hash:      hashAPIKey(key),
+		Scopes:    scopes,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, created, nil
+}
+
+func (s *APIKeyService) ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]*domain.APIKey, error) {
+	return s.repo.ListAPIKeysByUser(ctx, userID)
+}
+
+// RevokeAPIKey deletes the key if it belongs to the user.
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, userID, id uuid.UUID) error {
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		keys, err := s.repo.ListAPIKeysByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if !slices.ContainsFunc(keys, func(k *domain.APIKey) bool { return k.ID == id }) {
+			return fmt.Errorf("%w api key id: %s", repository.ErrNotFound, id.String())
+		}
+
+		_, err = s.repo.DeleteAPIKeyByID(ctx, id)
+		return err
+	})
+}
+
+// Authenticate returns the session the key acts as, or false if the key is
+// unknown or its account is disabled.
+func (s *APIKeyService) Authenticate(ctx context.Context, key string) (*domain.Session, bool, error) {
+	if !strings.HasPrefix(key, domain.APIKeyPrefix) {
+		return nil, false, nil
+	}
+
+	apiKey, err := s.repo.FindAPIKeyByHash(ctx, hashAPIKey(key))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, apiKey.UserID)
+	if err != nil || user.Disabled {
+		return nil, false, nil
+	}
+
+	now := time.Now()
+	if now.Sub(apiKey.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.repo.TouchAPIKey(ctx, apiKey.ID, now); err != nil {
+			return nil, false, err
+		}
+		apiKey.LastUsedAt = now
+	}
+
+	return &domain.Session{
+		UserID:       user.ID,
+		NotionUserID: user.NotionUserID,
+		APIKey:       apiKey,
+	}, true, nil
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/repository"
+)
+
+var ErrInvalidArchive = errors.New("invalid archive")
+
+type ArchiveService struct {
+	userRepo    repository.UserRepository
+	pageRepo    repository.NotionPageRepository
+	mindMapRepo repository.MindMapRepository
+	pageSvc     *NotionPageService
+	mindMapSvc  *MindMapService
+	uow         repository.UnitOfWork
+}
+
+func NewArchiveService(
+	userRepo repository.UserRepository,
+	pageRepo repository.NotionPageRepository,
+	mindMapRepo repository.MindMapRepository,
+	pageSvc *NotionPageService,
+	mindMapSvc *MindMapService,
+	uow repository.UnitOfWork,
+) *ArchiveService {
+	return &ArchiveService{
+		userRepo:    userRepo,
+		pageRepo:    pageRepo,
+		mindMapRepo: mindMapRepo,
+		pageSvc:     pageSvc,
+		mindMapSvc:  mindMapSvc,
+		uow:         uow,
+	}
+}
+
+// Export returns every piece of data owned by the user. The data is read in
+// one unit of work so the archive is consistent.
+func (s *ArchiveService) Export(ctx context.Context, userID uuid.UUID) (*domain.Archive, error) {
+	archive := &domain.Archive{
+		Header: &domain.ArchiveHeader{
+			Format:     domain.ArchiveFormat,
+			Version:    domain.ArchiveFormatVersion,
+			ExportedAt: time.Now().UTC(),
+			UserID:     userID,
+		},
+		MindMap: &domain.MindMapGraph{UserID: userID},
+	}
+
+	err := s.uow.View(ctx, func(ctx context.Context) error {
+		user, err := s.userRepo.FindUserByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		archive.User = user.Profile()
+
+		if archive.Pages, err = s.pageRepo.FindAllNotionPagesByUser(ctx, userID); err != nil {
+			return err
+		}
+		if archive.MindMap.Nodes, err = s.mindMapRepo.ListKeywordNodeByUser(ctx, userID); err != nil {
+			return err
+		}
+		archive.MindMap.Edges, err = s.mindMapRepo.ListKeywordEdgeByUser(ctx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return archive, nil
+}
+
+// Import recreates the notion pages and the mind map of an archive for the
+// user with fresh ids, keeping edges pointing at their nodes and nodes pointing
+// at their pages. In replace mode the existing pages and mind map of the user
+// are deleted first. In merge mode an archived notion page the user already
+// has is skipped and its nodes point at the existing page. The sync state of
+// imported notion pages is dropped, so the next sync fetches them again.
+func (s *ArchiveService) Import(
+	ctx context.Context,
+	userID uuid.UUID,
+	archive *domain.Archive,
+	mode string,
+) (*domain.ArchiveImportReport, error) {
+	if mode != domain.ImportModeMerge && mode != domain.ImportModeReplace {
+		return nil, fmt.Errorf("invalid import mode: %s", mode)
+	}
+
+	nodes, edges, err := indexMindMap(archive.MindMap)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.ArchiveImportReport{
+		Mode: mode,
+	}
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if _, err := s.userRepo.FindUserByID(ctx, userID); err != nil {
+			return err
+		}
+
+		if mode == domain.ImportModeReplace {
+			pages, err := s.pageRepo.FindAllNotionPagesByUser(ctx, userID)
+			if err != nil {
+				return err
+			}
+			for _, p := range pages {
+				if _, err := s.pageSvc.DeleteNotionPageByID(ctx, p.ID); err != nil {
+					return err
+				}
+			}
+			report.DeletedNotionPages = len(pages)
+
+			existing, err := s.mindMapRepo.ListKeywordNodeByUser(ctx, userID)
+			if err != nil {
+				return err
+			}
+			if err := s.mindMapSvc.DeleteMindMapByUser(ctx, userID); err != nil {
+				return err
+			}
+			report.DeletedKeywordNodes = len(existing)
+		}
+
+		// byNotionID maps the notion pages the user has to their ids. It is
+		// empty in replace mode as they were all deleted.
+		byNotionID := make(map[uuid.UUID]uuid.UUID)
+		if mode == domain.ImportModeMerge {
+			existing, err := s.pageRepo.FindAllNotionPagesByUser(ctx, userID)
+			if err != nil {
+				return err
+			}
+			for _, p := range existing {
+				if p.NotionPageID != uuid.Nil {
+					byNotionID[p.NotionPageID] = p.ID
+				}
+			}
+		}
+
+		pageIDs := make(map[uuid.UUID]uuid.UUID, len(archive.Pages))
+		oldPageIDs := make([]uuid.UUID, 0, len(archive.Pages))
+		pages := make([]*domain.NotionPage, 0, len(archive.Pages))
+		for _, p := range archive.Pages {
+			if p == nil {
+				continue
+			}
+			if p.NotionPageID != uuid.Nil {
+				if id, ok := byNotionID[p.NotionPageID]; ok {
+					pageIDs[p.ID] = id
+					report.SkippedNotionPages++
+					continue
+				}
+			}
+			oldPageIDs = append(oldPageIDs, p.ID)
+			copied := *p
+			copied.UserID = userID
+			if copied.NotionPageID != uuid.Nil {
+				resetSyncState(&copied)
+			}
+			pages = append(pages, &copied)
+		}
+		created, err := s.pageSvc.CreateNotionPages(ctx, pages)
+		if err != nil {
+			return err
+		}
+		report.NotionPages = len(created)
+
+		for i, p := range created {
+			pageIDs[oldPageIDs[i]] = p.ID
+		}
+		for _, n := range nodes {
+			if id, ok := pageIDs[n.NotionPageID]; ok {
+				n.NotionPageID = id
+			}
+		}
+
+		if err := s.mindMapSvc.BuildMindMap(ctx, userID, nodes, edges); err != nil {
+			return err
+		}
+		report.KeywordNodes = len(nodes)
+		report.KeywordEdges = len(edges)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// resetSyncState drops what the last sync recorded on page, which does not
+// hold for the copy in the archive.
+func resetSyncState(page *domain.NotionPage) {
+	page.LastEditedAt = time.Time{}
+	page.LastSyncedAt = time.Time{}
+	page.SyncStatus = ""
+	page.SyncError = ""
+	page.Markdown = ""
+	page.Outline = nil
+}
+
+// indexMindMap turns the edges of graph, which reference node ids, into edges
+// referencing the position of the nodes so the graph can be rebuilt with
+// fresh ids.
+func indexMindMap(graph *domain.MindMapGraph) ([]*domain.KeywordNode, []*domain.EdgeOfIndex, error) {
+	if graph == nil {
+		return nil, nil, nil
+	}
+
+	nodes := make([]*domain.KeywordNode, 0, len(graph.Nodes))
+	index := make(map[uuid.UUID]int, len(graph.Nodes))
+	for _, n := range graph.Nodes {
+		if n == nil {
+			continue
+		}
+		index[n.ID] = len(nodes)
+		copied := *n
+		nodes = append(nodes, &copied)
+	}
+
+	edges := make([]*domain.EdgeOfIndex, 0, len(graph.Edges))
+	for _, e := range graph.Edges {
+		if e == nil {
+			continue
+		}
+		idx1, ok1 := index[e.Keyword1]
+		idx2, ok2 := index[e.Keyword2]
+		if !ok1 || !ok2 {
+			return nil, nil, fmt.Errorf(
+				"%w: edge %s references an unknown keyword node",
+				ErrInvalidArchive,
+				e.ID.String(),
+			)
+		}
+		edges = append(edges, &domain.EdgeOfIndex{Idx1: idx1, Idx2: idx2})
+	}
+
+	return nodes, edges, nil
+}
+
+// ReadArchive decodes a JSON lines archive written from Archive.Records.
+func ReadArchive(r io.Reader) (*domain.Archive, error) {
+	dec := json.NewDecoder(r)
+	archive := &domain.Archive{}
+
+	for {
+		record := &struct {
+			Type string          `json:"type"`
+			Data json.RawMessage `json:"data"`
+		}{}
+		if err := dec.Decode(record); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		if archive.Header == nil && record.Type != domain.ArchiveRecordHeader {
+			return nil, errors.New("archive must start with a header record")
+		}
+
+		var err error
+		switch record.Type {
+		case domain.ArchiveRecordHeader:
+			if archive.Header != nil {
+				return nil, errors.New("archive has more than one header record")
+			}
+			archive.Header = &domain.ArchiveHeader{}
+			if err = json.Unmarshal(record.Data, archive.Header); err != nil {
+				break
+			}
+			if archive.Header.Format != domain.ArchiveFormat {
+				return nil, fmt.Errorf("unknown archive format: %s", archive.Header.Format)
+			}
+			if archive.Header.Version < 1 || archive.Header.Version > domain.ArchiveFormatVersion {
+				return nil, fmt.Errorf("unsupported archive version: %d", archive.Header.Version)
+			}
+		case domain.ArchiveRecordUser:
+			archive.User = &domain.UserProfile{}
+			err = json.Unmarshal(record.Data, archive.User)
+		case domain.ArchiveRecordNotionPage:
+			page := &domain.NotionPage{}
+			err = json.Unmarshal(record.Data, page)
+			archive.Pages = append(archive.Pages, page)
+		case domain.ArchiveRecordMindMap:
+			archive.MindMap = &domain.MindMapGraph{}
+			err = json.Unmarshal(record.Data, archive.MindMap)
+		default:
+			return nil, fmt.Errorf("unknown archive record type: %s", record.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s record: %w", record.Type, err)
+		}
+	}
+
+	if archive.Header == nil {
+		return nil, errors.New("empty archive")
+	}
+
+	return archive, nil
+}
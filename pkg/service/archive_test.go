@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/config"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/repository"
+)
+
+func TestArchiveServiceMergeSkipsExistingNotionPages(t *testing.T) {
+	ctx := context.Background()
+	repos, err := repository.New(ctx, &config.StorageConfig{Driver: repository.DriverMemory}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := NewArchiveService(
+		repos.User,
+		repos.NotionPage,
+		repos.MindMap,
+		NewNotionPageService(repos.NotionPage),
+		NewMindMapService(repos.MindMap, repos.UnitOfWork),
+		repos.UnitOfWork,
+	)
+
+	user, err := repos.User.CreateUser(ctx, &domain.User{Nickname: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	existingNotionID, newNotionID := uuid.New(), uuid.New()
+	existing, err := repos.NotionPage.CreateNotionPage(ctx, &domain.NotionPage{
+		UserID:       user.ID,
+		Content:      "current",
+		NotionPageID: existingNotionID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	synced := time.Now().UTC()
+	archivedExisting := &domain.NotionPage{ID: uuid.New(), Content: "old", NotionPageID: existingNotionID}
+	archivedNew := &domain.NotionPage{
+		ID:           uuid.New(),
+		Content:      "new",
+		NotionPageID: newNotionID,
+		LastEditedAt: synced.Add(-time.Hour),
+		LastSyncedAt: synced,
+		SyncStatus:   domain.NotionPageSynced,
+		Markdown:     "new",
+	}
+	archive := &domain.Archive{
+		Pages: []*domain.NotionPage{archivedExisting, archivedNew},
+		MindMap: &domain.MindMapGraph{Nodes: []*domain.KeywordNode{
+			{ID: uuid.New(), NotionPageID: archivedExisting.ID, Keyword: "kept"},
+		}},
+	}
+
+	report, err := svc.Import(ctx, user.ID, archive, domain.ImportModeMerge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.NotionPages != 1 || report.SkippedNotionPages != 1 {
+		t.Fatalf("imported %d and skipped %d pages, want 1 and 1", report.NotionPages, report.SkippedNotionPages)
+	}
+
+	pages, err := repos.NotionPage.FindAllNotionPagesByUser(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 2 {
+		t.Fatalf("user has %d pages after the merge, want 2", len(pages))
+	}
+	for _, p := range pages {
+		switch p.NotionPageID {
+		case existingNotionID:
+			if p.ID != existing.ID || p.Content != "current" {
+				t.Fatalf("existing page became %+v", p)
+			}
+		case newNotionID:
+			if p.SyncStatus != "" || !p.LastSyncedAt.IsZero() || !p.LastEditedAt.IsZero() || p.Markdown != "" {
+				t.Fatalf("imported page kept the sync state %+v", p)
+			}
+		}
+	}
+
+	nodes, err := repos.MindMap.ListKeywordNodeByUser(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 1 || nodes[0].NotionPageID != existing.ID {
+		t.Fatalf("keyword nodes %+v, want one pointing at the existing page", nodes)
+	}
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/repository"
+)
+
+const backupChecksumAlgorithm = "sha256"
+
+type BackupService struct {
+	userRepo    repository.UserRepository
+	pageRepo    repository.NotionPageRepository
+	mindMapRepo repository.MindMapRepository
+	apiKeyRepo  repository.APIKeyRepository
+	uow         repository.UnitOfWork
+}
+
+func NewBackupService(
+	userRepo repository.UserRepository,
+	pageRepo repository.NotionPageRepository,
+	mindMapRepo repository.MindMapRepository,
+	apiKeyRepo repository.APIKeyRepository,
+	uow repository.UnitOfWork,
+) *BackupService {
+	return &BackupService{
+		userRepo:    userRepo,
+		pageRepo:    pageRepo,
+		mindMapRepo: mindMapRepo,
+		apiKeyRepo:  apiKeyRepo,
+		uow:         uow,
+	}
+}
+
+// Backup writes every user, page, keyword and api key of the storage backend to w,
+// followed by a checksum of everything written before it.
+func (s *BackupService) Backup(
+	ctx context.Context,
+	w io.Writer,
+	driver string,
+) (*domain.BackupReport, error) {
+	backup := &domain.Backup{
+		Header: &domain.BackupHeader{
+			Format:    domain.BackupFormat,
+			Version:   domain.BackupFormatVersion,
+			CreatedAt: time.Now().UTC(),
+			Driver:    driver,
+		},
+	}
+
+	err := s.uow.View(ctx, func(ctx context.Context) error {
+		var err error
+		if backup.Users, err = s.userRepo.ListUsers(ctx); err != nil {
+			return err
+		}
+		if backup.NotionPages, err = s.pageRepo.ListNotionPages(ctx); err != nil {
+			return err
+		}
+		if backup.KeywordNodes, err = s.mindMapRepo.ListKeywordNodes(ctx); err != nil {
+			return err
+		}
+		if backup.KeywordEdges, err = s.mindMapRepo.ListKeywordEdges(ctx); err != nil {
+			return err
+		}
+		backup.APIKeys, err = s.apiKeyRepo.ListAPIKeys(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.New()
+	enc := json.NewEncoder(io.MultiWriter(w, sum))
+	records := backup.Records()
+	for _, record := range records {
+		if err := enc.Encode(record); err != nil {
+			return nil, err
+		}
+	}
+
+	err = json.NewEncoder(w).Encode(&domain.ArchiveRecord{
+		Type: domain.BackupRecordChecksum,
+		Data: &domain.BackupChecksum{
+			Algorithm: backupChecksumAlgorithm,
+			Sum:       hex.EncodeToString(sum.Sum(nil)),
+			Records:   len(records),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return backup.Report(), nil
+}
+
+// Restore loads a backup written by Backup into the storage backend. The whole
+// backup is verified before anything is written, and the backend must be
+// empty so ids never collide.
+func (s *BackupService) Restore(ctx context.Context, r io.Reader) (*domain.BackupReport, error) {
+	backup, err := readBackup(r)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.checkEmpty(ctx); err != nil {
+			return err
+		}
+
+		if err := s.userRepo.RestoreUsers(ctx, backup.Users...); err != nil {
+			return err
+		}
+		if err := s.pageRepo.RestoreNotionPages(ctx, backup.NotionPages...); err != nil {
+			return err
+		}
+		if err := s.mindMapRepo.RestoreBulkKeywordNodes(ctx, backup.KeywordNodes...); err != nil {
+			return err
+		}
+		if err := s.mindMapRepo.RestoreBulkKeywordEdges(ctx, backup.KeywordEdges...); err != nil {
+			return err
+		}
+		return s.apiKeyRepo.RestoreAPIKeys(ctx, backup.APIKeys...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return backup.Report(), nil
+}
+
+func (s *BackupService) checkEmpty(ctx context.Context) error {
+	users, err := s.userRepo.ListUsers(ctx)
+	if err != nil {
+		return err
+	}
+	pages, err := s.pageRepo.ListNotionPages(ctx)
+	if err != nil {
+		return err
+	}
+	nodes, err := s.mindMapRepo.ListKeywordNodes(ctx)
+	if err != nil {
+		return err
+	}
+	edges, err := s.mindMapRepo.ListKeywordEdges(ctx)
+	if err != nil {
+		return err
+	}
+	keys, err := s.apiKeyRepo.ListAPIKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(users)+len(pages)+len(nodes)+len(edges)+len(keys) > 0 {
+		return errors.New("storage is not empty, restore only into a fresh installation")
+	}
+
+	return nil
+}
+
+func readBackup(r io.Reader) (*domain.Backup, error) {
+	br := bufio.NewReader(r)
+	sum := sha256.New()
+	backup := &domain.Backup{}
+	records := 0
+
+	for {
+		line, err := br.ReadBytes('\n')
+		if errors.Is(err, io.EOF) && len(bytes.TrimSpace(line)) == 0 {
+			return nil, errors.New("backup is truncated: missing checksum record")
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+		record := &struct {
+			Type string          `json:"type"`
+			Data json.RawMessage `json:"data"`
+		}{}
+		if err := json.Unmarshal(line, record); err != nil {
+			return nil, fmt.Errorf("invalid backup record %d: %w", records+1, err)
+		}
+
+		if record.Type == domain.BackupRecordChecksum {
+			if err := verifyBackupChecksum(record.Data, sum, records); err != nil {
+				return nil, err
+			}
+			if rest, _ := io.ReadAll(br); len(bytes.TrimSpace(rest)) > 0 {
+				return nil, errors.New("backup has data after the checksum record")
+			}
+			break
+		}
+
+		if err := decodeBackupRecord(backup, record.Type, record.Data); err != nil {
+			return nil, fmt.Errorf("invalid backup record %d: %w", records+1, err)
+		}
+		sum.Write(line)
+		records++
+	}
+
+	if backup.Header == nil {
+		return nil, errors.New("backup has no header record")
+	}
+
+	return backup, nil
+}
+
+func decodeBackupRecord(backup *domain.Backup, typ string, data json.RawMessage) error {
+	if backup.Header == nil && typ != domain.BackupRecordHeader {
+		return errors.New("backup must start with a header record")
+	}
+
+	switch typ {
+	case domain.BackupRecordHeader:
+		if backup.Header != nil {
+			return errors.New("backup has more than one header record")
+		}
+		backup.Header = &domain.BackupHeader{}
+		if err := json.Unmarshal(data, backup.Header); err != nil {
+			return err
+		}
+		if backup.Header.Format != domain.BackupFormat {
+			return fmt.Errorf("unknown backup format: %s", backup.Header.Format)
+		}
+		if backup.Header.Version < 1 || backup.Header.Version > domain.BackupFormatVersion {
+			return fmt.Errorf("unsupported backup version: %d", backup.Header.Version)
+		}
+		return nil
+	case domain.BackupRecordUser:
+		record := &domain.UserRecord{}
+		if err := json.Unmarshal(data, record); err != nil {
+			return err
+		}
+		backup.Users = append(backup.Users, record.ToUser())
+		return nil
+	case domain.BackupRecordNotionPage:
+		return appendDecoded(&backup.NotionPages, data)
+	case domain.BackupRecordKeywordNode:
+		return appendDecoded(&backup.KeywordNodes, data)
+	case domain.BackupRecordKeywordEdge:
+		return appendDecoded(&backup.KeywordEdges, data)
+	case domain.BackupRecordAPIKey:
+		if backup.Header.Version < 2 {
+			return fmt.Errorf("backup version %d has no %s records", backup.Header.Version, typ)
+		}
+		return appendDecoded(&backup.APIKeys, data)
+	default:
+		return fmt.Errorf("unknown backup record type: %s", typ)
+	}
+}
+
+func appendDecoded[T any](list *[]*T, data json.RawMessage) error {
+	v := new(T)
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+	*list = append(*list, v)
+
+	return nil
+}
+
+func verifyBackupChecksum(data json.RawMessage, sum hash.Hash, records int) error {
+	checksum := &domain.BackupChecksum{}
+	if err := json.Unmarshal(data, checksum); err != nil {
+		return fmt.Errorf("invalid checksum record: %w", err)
+	}
+	if checksum.Algorithm != backupChecksumAlgorithm {
+		return fmt.Errorf("unknown checksum algorithm: %s", checksum.Algorithm)
+	}
+	if checksum.Records != records {
+		return fmt.Errorf("backup has %d records, checksum expects %d", records, checksum.Records)
+	}
+	if checksum.Sum != hex.EncodeToString(sum.Sum(nil)) {
+		return errors.New("backup checksum mismatch, the file is corrupted")
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/config"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/repository"
+)
+
+func newTestBackupService(repos *repository.Repositories) *BackupService {
+	return NewBackupService(repos.User, repos.NotionPage, repos.MindMap, repos.APIKey, repos.UnitOfWork)
+}
+
+func TestBackupServiceRestoresAPIKeys(t *testing.T) {
+	ctx := context.Background()
+	encryption := &config.EncryptionConfig{Key: newTestEncryptionKey(t, "key1")}
+
+	source := openTestBolt(t, filepath.Join(t.TempDir(), "source.db"), encryption)
+	user, err := source.User.CreateUser(ctx, &domain.User{Nickname: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := source.APIKey.CreateAPIKey(ctx, &domain.APIKey{
+		UserID:    user.ID,
+		Name:      "script",
+		Hash:      "hash",
+		Scopes:    []string{domain.ScopeRead},
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := newTestBackupService(source).Backup(ctx, &buf, repository.DriverBolt); err != nil {
+		t.Fatal(err)
+	}
+
+	target := openTestBolt(t, filepath.Join(t.TempDir(), "target.db"), encryption)
+	report, err := newTestBackupService(target).Restore(ctx, bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Users != 1 || report.APIKeys != 1 {
+		t.Fatalf("restored %d users and %d api keys, want 1 and 1", report.Users, report.APIKeys)
+	}
+
+	restored, err := target.APIKey.FindAPIKeyByHash(ctx, "hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.ID != key.ID || restored.UserID != user.ID || !restored.HasScope(domain.ScopeRead) {
+		t.Fatalf("restored api key %+v, want %+v", restored, key)
+	}
+
+	// With the user gone only the api key is left, which still blocks a restore.
+	if _, err := target.User.DeleteUserByID(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	_, err = newTestBackupService(target).Restore(ctx, bytes.NewReader(buf.Bytes()))
+	if err == nil || !strings.Contains(err.Error(), "not empty") {
+		t.Fatalf("restore into storage holding api keys = %v, want not empty error", err)
+	}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/repository"
+)
+
+type EncryptionService struct {
+	userRepo repository.UserRepository
+	sessions repository.SessionStore
+	cipher   *repository.TokenCipher
+	uow      repository.UnitOfWork
+}
+
+func NewEncryptionService(
+	userRepo repository.UserRepository,
+	sessions repository.SessionStore,
+	cipher *repository.TokenCipher,
+	uow repository.UnitOfWork,
+) *EncryptionService {
+	return &EncryptionService{
+		userRepo: userRepo,
+		sessions: sessions,
+		cipher:   cipher,
+		uow:      uow,
+	}
+}
+
+// Reencrypt rewrites the notion tokens of every user and session, which
+// encrypts them under the current key whichever key or none they were stored
+// with. Once it has run the old keys can be dropped from the configuration.
+func (s *EncryptionService) Reencrypt(ctx context.Context) (*domain.ReencryptReport, error) {
+	if s.cipher == nil || !s.cipher.Enabled() {
+		return nil, repository.ErrNoEncryptionKey
+	}
+
+	report := &domain.ReencryptReport{}
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		users, err := s.userRepo.ListUsers(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, u := range users {
+			if _, err := s.userRepo.UpdateUser(ctx, u); err != nil {
+				return err
+			}
+			report.Users++
+
+			if s.sessions == nil {
+				continue
+			}
+			sessions, err := s.sessions.ListByUser(ctx, u.ID)
+			if err != nil {
+				return err
+			}
+			for _, session := range sessions {
+				if err := s.sessions.Set(ctx, session); err != nil {
+					return err
+				}
+				report.Sessions++
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
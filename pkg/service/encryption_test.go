@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/config"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/repository"
+)
+
+func newTestEncryptionKey(t *testing.T, id string) string {
+	t.Helper()
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		t.Fatal(err)
+	}
+
+	return id + ":" + base64.StdEncoding.EncodeToString(raw)
+}
+
+// openTestBolt opens the bolt database at path with the given encryption and
+// closes it when the test ends.
+func openTestBolt(t *testing.T, path string, encryption *config.EncryptionConfig) *repository.Repositories {
+	t.Helper()
+
+	repos, err := repository.New(context.Background(),
+		&config.StorageConfig{Driver: repository.DriverBolt, Path: path}, nil, encryption)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { repos.Close() })
+
+	return repos
+}
+
+func TestEncryptionServiceReencrypt(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "mindmap.db")
+	oldKey := newTestEncryptionKey(t, "key1")
+	newKey := newTestEncryptionKey(t, "key2")
+
+	repos := openTestBolt(t, path, &config.EncryptionConfig{Key: oldKey})
+	user, err := repos.User.CreateUser(ctx, &domain.User{
+		Nickname:     "alice",
+		AccessToken:  "secret_access",
+		RefreshToken: "secret_refresh",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = repos.Sessions.Set(ctx, &domain.Session{
+		ID:         "session",
+		UserID:     user.ID,
+		Token:      &domain.Token{AccessToken: "secret_access", RefreshToken: "secret_refresh"},
+		CreatedAt:  time.Now(),
+		LastSeenAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repos.Close()
+
+	repos = openTestBolt(t, path, &config.EncryptionConfig{Key: newKey, OldKeys: []string{oldKey}})
+	svc := NewEncryptionService(repos.User, repos.Sessions, repos.Cipher, repos.UnitOfWork)
+	report, err := svc.Reencrypt(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Users != 1 || report.Sessions != 1 {
+		t.Fatalf("re-encrypted %d users and %d sessions, want 1 and 1", report.Users, report.Sessions)
+	}
+	repos.Close()
+
+	// Without the old key only values written under the new one are readable.
+	repos = openTestBolt(t, path, &config.EncryptionConfig{Key: newKey})
+	found, err := repos.User.FindUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("user is unreadable without the old key: %v", err)
+	}
+	if found.AccessToken != "secret_access" || found.RefreshToken != "secret_refresh" {
+		t.Fatalf("tokens = %q, %q after re-encryption", found.AccessToken, found.RefreshToken)
+	}
+	session, ok, err := repos.Sessions.Get(ctx, "session")
+	if err != nil || !ok {
+		t.Fatalf("session is unreadable without the old key: %t, %v", ok, err)
+	}
+	if session.Token.AccessToken != "secret_access" {
+		t.Fatalf("session access token = %q after re-encryption", session.Token.AccessToken)
+	}
+}
+
+func TestEncryptionServiceReencryptWrongKey(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "mindmap.db")
+
+	repos := openTestBolt(t, path, &config.EncryptionConfig{Key: newTestEncryptionKey(t, "key1")})
+	if _, err := repos.User.CreateUser(ctx, &domain.User{AccessToken: "secret_access"}); err != nil {
+		t.Fatal(err)
+	}
+	repos.Close()
+
+	// The old key was dropped before the rotation finished.
+	repos = openTestBolt(t, path, &config.EncryptionConfig{Key: newTestEncryptionKey(t, "key2")})
+	svc := NewEncryptionService(repos.User, repos.Sessions, repos.Cipher, repos.UnitOfWork)
+	if _, err := svc.Reencrypt(ctx); err == nil {
+		t.Fatal("Reencrypt succeeded without the key the tokens were sealed with")
+	}
+}
+
+func TestEncryptionServiceReencryptWithoutKey(t *testing.T) {
+	repos := openTestBolt(t, filepath.Join(t.TempDir(), "mindmap.db"),
+		&config.EncryptionConfig{AllowPlaintextTokens: true})
+	svc := NewEncryptionService(repos.User, repos.Sessions, repos.Cipher, repos.UnitOfWork)
+
+	if _, err := svc.Reencrypt(context.Background()); !errors.Is(err, repository.ErrNoEncryptionKey) {
+		t.Fatalf("Reencrypt without a key = %v, want %v", err, repository.ErrNoEncryptionKey)
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// userLocks hands out one mutex per user. A user's mutex only stays in the
+// map while callers hold or wait for it, so the map does not grow with every
+// user ever seen.
+type userLocks struct {
+	mu    sync.Mutex
+	locks map[uuid.UUID]*userLock
+}
+
+type userLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+func newUserLocks() *userLocks {
+	return &userLocks{
+		locks: make(map[uuid.UUID]*userLock),
+	}
+}
+
+// lock takes the lock of the user and returns the function releasing it.
+// The last caller to release the lock removes it from the map.
+func (l *userLocks) lock(userID uuid.UUID) func() {
+	l.mu.Lock()
+	lock, ok := l.locks[userID]
+	if !ok {
+		lock = &userLock{}
+		l.locks[userID] = lock
+	}
+	lock.refs++
+	l.mu.Unlock()
+
+	lock.mu.Lock()
+
+	return func() {
+		lock.mu.Unlock()
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		lock.refs--
+		if lock.refs == 0 {
+			delete(l.locks, userID)
+		}
+	}
+}
+
+// held returns how many users have a lock in the map.
+func (l *userLocks) held() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.locks)
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestUserLocksReleaseLocks(t *testing.T) {
+	locks := newUserLocks()
+	userIDs := []uuid.UUID{uuid.New(), uuid.New()}
+
+	held := make([]int, len(userIDs))
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		n := i % len(userIDs)
+		userID := userIDs[n]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := locks.lock(userID)
+			defer unlock()
+
+			// Only the lock of the user guards held[n], so the race detector
+			// catches two holders of one user.
+			held[n]++
+			if held[n] != 1 {
+				t.Errorf("lock of user %s is held %d times", userID, held[n])
+			}
+			held[n]--
+		}()
+	}
+	wg.Wait()
+
+	if held := locks.held(); held != 0 {
+		t.Fatalf("%d user locks are left after every caller released them", held)
+	}
+}
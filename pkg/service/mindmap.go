@@ -10,12 +10,14 @@ import (
 )
 
 type MindMapService struct {
-	repo *repository.MemoryMindMapRepo
+	repo repository.MindMapRepository
+	uow  repository.UnitOfWork
 }
 
-func NewMindMapService(repo *repository.MemoryMindMapRepo) *MindMapService {
+func NewMindMapService(repo repository.MindMapRepository, uow repository.UnitOfWork) *MindMapService {
 	return &MindMapService{
 		repo: repo,
+		uow:  uow,
 	}
 }
 
@@ -25,32 +27,24 @@ func (s *MindMapService) BuildMindMap(
 	nodes []*domain.KeywordNode,
 	edges []*domain.EdgeOfIndex,
 ) error {
-	s.repo.BeginTransaction(ctx)
-	defer s.repo.Commit(ctx)
-
-	for _, n := range nodes {
-		n.UserID = userID
-	}
-	newNodes, err := s.repo.CreateBulkKeywordNodes(ctx, nodes...)
-	if err != nil {
-		s.repo.Abort(ctx)
-		return err
-	}
-
-	keywordEdges := make([]*domain.KeywordEdge, 0, len(edges))
-	for _, e := range edges {
-		keywordEdges = append(keywordEdges, &domain.KeywordEdge{
-			UserID:   userID,
-			Keyword1: newNodes[e.Idx1].ID,
-			Keyword2: newNodes[e.Idx2].ID,
-		})
-	}
-	if _, err := s.repo.CreateBulkKeywordEdges(ctx, keywordEdges...); err != nil {
-		s.repo.Abort(ctx)
-		return err
-	}
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		return s.buildMindMap(ctx, userID, nodes, edges)
+	})
+}
 
-	return nil
+// RebuildMindMap replaces the whole mind map of the user in one unit of work.
+func (s *MindMapService) RebuildMindMap(
+	ctx context.Context,
+	userID uuid.UUID,
+	nodes []*domain.KeywordNode,
+	edges []*domain.EdgeOfIndex,
+) error {
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.deleteMindMapByUser(ctx, userID); err != nil {
+			return err
+		}
+		return s.buildMindMap(ctx, userID, nodes, edges)
+	})
 }
 
 func (s *MindMapService) GetMindMapByUser(
@@ -76,32 +70,136 @@ func (s *MindMapService) GetMindMapByUser(
 }
 
 func (s *MindMapService) DeleteMindMapByUser(ctx context.Context, userID uuid.UUID) error {
-	s.repo.BeginTransaction(ctx)
-	defer s.repo.Commit(ctx)
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		return s.deleteMindMapByUser(ctx, userID)
+	})
+}
+
+// ReconcileNotionPages brings the mind map of the user in line with a notion
+// sync in one unit of work. The keywords of the removed pages, and the edges
+// between them, are taken out of the mind map. The keywords of the changed
+// pages are kept but flagged stale until the mind map is rebuilt from the new
+// content. It returns how many keyword nodes were pruned and flagged.
+func (s *MindMapService) ReconcileNotionPages(
+	ctx context.Context,
+	userID uuid.UUID,
+	removed, changed []uuid.UUID,
+) (pruned, stale int, err error) {
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if pruned, err = s.pruneNotionPages(ctx, userID, removed); err != nil {
+			return err
+		}
+		if len(changed) == 0 {
+			return nil
+		}
+		stale, err = s.repo.MarkKeywordNodesStale(ctx, changed)
+		return err
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return pruned, stale, nil
+}
+
+func (s *MindMapService) pruneNotionPages(
+	ctx context.Context,
+	userID uuid.UUID,
+	pageIDs []uuid.UUID,
+) (int, error) {
+	if len(pageIDs) == 0 {
+		return 0, nil
+	}
+
+	pages := make(map[uuid.UUID]bool, len(pageIDs))
+	for _, id := range pageIDs {
+		pages[id] = true
+	}
+
+	nodes, err := s.repo.ListKeywordNodeByUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	pruned := make(map[uuid.UUID]bool)
+	for _, n := range nodes {
+		if pages[n.NotionPageID] {
+			pruned[n.ID] = true
+		}
+	}
+	if len(pruned) == 0 {
+		return 0, nil
+	}
+
+	edges, err := s.repo.ListKeywordEdgeByUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	edgeIDs := make([]uuid.UUID, 0)
+	for _, e := range edges {
+		if pruned[e.Keyword1] || pruned[e.Keyword2] {
+			edgeIDs = append(edgeIDs, e.ID)
+		}
+	}
+	if _, err := s.repo.DeleteBulkKeywordEdges(ctx, edgeIDs); err != nil {
+		return 0, err
+	}
 
+	nodeIDs := make([]uuid.UUID, 0, len(pruned))
+	for id := range pruned {
+		nodeIDs = append(nodeIDs, id)
+	}
+	deleted, err := s.repo.DeleteBulkKeywordNodes(ctx, nodeIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(deleted), nil
+}
+
+func (s *MindMapService) buildMindMap(
+	ctx context.Context,
+	userID uuid.UUID,
+	nodes []*domain.KeywordNode,
+	edges []*domain.EdgeOfIndex,
+) error {
+	for _, n := range nodes {
+		n.UserID = userID
+		n.Stale = false
+	}
+	newNodes, err := s.repo.CreateBulkKeywordNodes(ctx, nodes...)
+	if err != nil {
+		return err
+	}
+
+	keywordEdges := make([]*domain.KeywordEdge, 0, len(edges))
+	for _, e := range edges {
+		keywordEdges = append(keywordEdges, &domain.KeywordEdge{
+			UserID:   userID,
+			Keyword1: newNodes[e.Idx1].ID,
+			Keyword2: newNodes[e.Idx2].ID,
+		})
+	}
+	_, err = s.repo.CreateBulkKeywordEdges(ctx, keywordEdges...)
+	return err
+}
+
+func (s *MindMapService) deleteMindMapByUser(ctx context.Context, userID uuid.UUID) error {
 	nodes, err := s.repo.ListKeywordNodeByUser(ctx, userID)
 	if err != nil {
-		s.repo.Abort(ctx)
 		return err
 	}
 
 	ids := domain.ExtractIDFromBulkNodes(nodes)
 	if _, err := s.repo.DeleteBulkKeywordNodes(ctx, ids); err != nil {
-		s.repo.Abort(ctx)
 		return err
 	}
 
 	edges, err := s.repo.ListKeywordEdgeByUser(ctx, userID)
 	if err != nil {
-		s.repo.Abort(ctx)
 		return err
 	}
 
 	ids = domain.ExtractIDFromBulkEdges(edges)
-	if _, err := s.repo.DeleteBulkKeywordEdges(ctx, ids); err != nil {
-		s.repo.Abort(ctx)
-		return err
-	}
-
-	return nil
+	_, err = s.repo.DeleteBulkKeywordEdges(ctx, ids)
+	return err
 }
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/repository"
+)
+
+func TestMindMapServiceReconcileNotionPages(t *testing.T) {
+	ctx := context.Background()
+	txManager := repository.NewMemoryTxManager()
+	repo := repository.NewMemoryMindMapRepo(txManager)
+	svc := NewMindMapService(repo, repository.NewUnitOfWork(txManager))
+
+	userID := uuid.New()
+	removed, changed, untouched := uuid.New(), uuid.New(), uuid.New()
+	err := svc.BuildMindMap(ctx, userID,
+		[]*domain.KeywordNode{
+			{NotionPageID: removed, Keyword: "gone"},
+			{NotionPageID: changed, Keyword: "edited"},
+			{NotionPageID: changed, Keyword: "edited too"},
+			{NotionPageID: untouched, Keyword: "kept"},
+		},
+		[]*domain.EdgeOfIndex{{Idx1: 0, Idx2: 1}, {Idx1: 1, Idx2: 3}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pruned, stale, err := svc.ReconcileNotionPages(ctx, userID, []uuid.UUID{removed}, []uuid.UUID{changed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pruned != 1 || stale != 2 {
+		t.Fatalf("pruned %d and flagged %d keyword nodes, want 1 and 2", pruned, stale)
+	}
+
+	mindmap := svc.GetMindMapByUser(ctx, userID)
+	if len(mindmap.Nodes) != 3 || len(mindmap.Edges) != 1 {
+		t.Fatalf("mind map has %d nodes and %d edges, want 3 and 1", len(mindmap.Nodes), len(mindmap.Edges))
+	}
+	for _, n := range mindmap.Nodes {
+		if n.Stale != (n.NotionPageID == changed) {
+			t.Fatalf("keyword %q has stale %v", n.Keyword, n.Stale)
+		}
+	}
+
+	// Flagging again does not count the nodes which are already stale.
+	if _, stale, err = svc.ReconcileNotionPages(ctx, userID, nil, []uuid.UUID{changed}); err != nil {
+		t.Fatal(err)
+	}
+	if stale != 0 {
+		t.Fatalf("flagged %d keyword nodes again, want 0", stale)
+	}
+
+	// Rebuilding from the new content replaces the stale nodes.
+	err = svc.RebuildMindMap(ctx, userID,
+		[]*domain.KeywordNode{{NotionPageID: changed, Keyword: "rewritten", Stale: true}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mindmap = svc.GetMindMapByUser(ctx, userID)
+	if len(mindmap.Nodes) != 1 || mindmap.Nodes[0].Stale {
+		t.Fatalf("rebuilt mind map has nodes %+v, want one fresh node", mindmap.Nodes)
+	}
+}
@@ -10,10 +10,10 @@ import (
 )
 
 type NotionPageService struct {
-	repo *repository.MemoryNotionPageRepo
+	repo repository.NotionPageRepository
 }
 
-func NewNotionPageService(repo *repository.MemoryNotionPageRepo) *NotionPageService {
+func NewNotionPageService(repo repository.NotionPageRepository) *NotionPageService {
 	return &NotionPageService{
 		repo: repo,
 	}
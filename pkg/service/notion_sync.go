@@ -0,0 +1,529 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/notion"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/repository"
+)
+
+const (
+	// maxBlockDepth bounds how deep nested blocks are read into the content.
+	maxBlockDepth = 8
+	// editedTimePrecision is how coarse notion reports last_edited_time. An
+	// edit made within it after a sync does not move the reported time.
+	editedTimePrecision = time.Minute
+	// syncQueueSize bounds the page syncs waiting for the worker. Pages which
+	// do not fit are left to the scheduled sync.
+	syncQueueSize = 256
+)
+
+// NotionReader reads the pages shared with the integration. It fails with
+// ErrNotionUnauthorized when notion rejects the access token.
+type NotionReader interface {
+	Search(ctx context.Context, accessToken string, params *notion.SearchParams) (*notion.List[*notion.Page], error)
+	GetPage(ctx context.Context, accessToken, pageID string) (*notion.Page, error)
+	ListBlockChildren(ctx context.Context, accessToken, blockID string) ([]*notion.Block, error)
+}
+
+// NotionSyncService imports the notion pages of a user with their stored
+// token, so the client does not have to upload the content.
+type NotionSyncService struct {
+	repo    repository.NotionPageRepository
+	users   *UserService
+	mindMap *MindMapService
+	reader  NotionReader
+	tokens  *TokenManager
+	queue   chan *pageSyncJob
+	// locks keeps a scheduled and a requested sync of the same user from
+	// running at once.
+	locks *userLocks
+}
+
+// pageSyncJob asks to sync a single page of a user.
+type pageSyncJob struct {
+	userID  uuid.UUID
+	pageID  string
+	deleted bool
+}
+
+func NewNotionSyncService(
+	repo repository.NotionPageRepository,
+	users *UserService,
+	mindMap *MindMapService,
+	reader NotionReader,
+	tokens *TokenManager,
+) *NotionSyncService {
+	return &NotionSyncService{
+		repo:    repo,
+		users:   users,
+		mindMap: mindMap,
+		reader:  reader,
+		tokens:  tokens,
+		queue:   make(chan *pageSyncJob, syncQueueSize),
+		locks:   newUserLocks(),
+	}
+}
+
+// notionSync is one pass over the pages shared with the integration.
+type notionSync struct {
+	userID      uuid.UUID
+	accessToken string
+	byNotionID  map[uuid.UUID]*domain.NotionPage
+	seen        map[uuid.UUID]bool
+	// removed are the pages whose keywords are pruned from the mind map.
+	removed []uuid.UUID
+	// changed are the pages whose keywords no longer match their content.
+	changed []uuid.UUID
+	report  *domain.NotionSyncReport
+}
+
+// Sync creates or updates a NotionPage, keyed by NotionPageID, for every page
+// shared with the integration. Only pages edited in notion since the last sync
+// are fetched again. A page which cannot be read is counted as failed and the
+// others are still synced. Synced pages which are gone from notion are marked
+// removed and their keywords are pruned from the mind map; the keywords of
+// updated pages are flagged stale.
+func (s *NotionSyncService) Sync(ctx context.Context, userID uuid.UUID) (*domain.NotionSyncReport, error) {
+	unlock := s.locks.lock(userID)
+	defer unlock()
+
+	var run *notionSync
+	err := s.tokens.Do(ctx, userID, func(ctx context.Context, accessToken string) error {
+		// Do calls this once more after refreshing the token, so it starts
+		// over with a fresh pass.
+		existing, err := s.repo.FindAllNotionPagesByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		run = &notionSync{
+			userID:      userID,
+			accessToken: accessToken,
+			byNotionID:  make(map[uuid.UUID]*domain.NotionPage, len(existing)),
+			seen:        make(map[uuid.UUID]bool),
+			report:      &domain.NotionSyncReport{},
+		}
+		for _, page := range existing {
+			run.byNotionID[page.NotionPageID] = page
+		}
+
+		cursor := ""
+		for {
+			list, err := s.reader.Search(ctx, accessToken, &notion.SearchParams{
+				Filter:      "page",
+				StartCursor: cursor,
+				PageSize:    notion.MaxPageSize,
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, page := range list.Results {
+				if page.Archived || page.InTrash {
+					continue
+				}
+				if err := s.syncPage(ctx, run, page, false); errors.Is(err, ErrNotionUnauthorized) {
+					return err
+				}
+			}
+
+			if !list.HasMore {
+				break
+			}
+			cursor = list.NextCursor
+		}
+
+		return s.markRemoved(ctx, run)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.reconcileMindMap(ctx, run); err != nil {
+		return nil, err
+	}
+
+	return run.report, nil
+}
+
+// reconcileMindMap prunes the keywords of the removed pages and flags those of
+// the changed pages.
+func (s *NotionSyncService) reconcileMindMap(ctx context.Context, run *notionSync) error {
+	pruned, stale, err := s.mindMap.ReconcileNotionPages(ctx, run.userID, run.removed, run.changed)
+	if err != nil {
+		return err
+	}
+	run.report.PrunedKeywordNodes = pruned
+	run.report.StaleKeywordNodes = stale
+
+	return nil
+}
+
+// syncPage brings the NotionPage of page up to date, fetching it even if it
+// looks up to date when force is set. Failures other than a rejected token are
+// recorded on the page and in the report.
+func (s *NotionSyncService) syncPage(ctx context.Context, run *notionSync, page *notion.Page, force bool) error {
+	err := s.fetchPage(ctx, run, page, force)
+	if err == nil || errors.Is(err, ErrNotionUnauthorized) {
+		return err
+	}
+
+	run.report.Failed++
+	run.report.Failures = append(run.report.Failures, &domain.NotionSyncFailure{
+		NotionPageID: page.ID,
+		Error:        err.Error(),
+	})
+
+	notionPageID, parseErr := uuid.Parse(page.ID)
+	if parseErr != nil {
+		return err
+	}
+	if current, ok := run.byNotionID[notionPageID]; ok {
+		failed := *current
+		failed.SyncStatus = domain.NotionPageFailed
+		failed.SyncError = err.Error()
+		if _, err := s.repo.UpdateNotionPage(ctx, &failed); err != nil {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (s *NotionSyncService) fetchPage(ctx context.Context, run *notionSync, page *notion.Page, force bool) error {
+	notionPageID, err := uuid.Parse(page.ID)
+	if err != nil {
+		return err
+	}
+	run.seen[notionPageID] = true
+
+	current, ok := run.byNotionID[notionPageID]
+	if ok && !force && upToDate(current, page) {
+		run.report.Unchanged++
+		return nil
+	}
+
+	blocks, err := s.readBlocks(ctx, run.accessToken, page.ID, 0)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	title := page.PageTitle()
+	if title != "" {
+		b.WriteString(title)
+		b.WriteString("\n")
+	}
+	writeText(&b, blocks, 0)
+	content := strings.TrimSpace(b.String())
+	markdown := notion.Markdown(title, blocks)
+	outline := toOutline(notion.Outline(blocks))
+
+	now := time.Now()
+	if !ok {
+		created, err := s.repo.CreateNotionPage(ctx, &domain.NotionPage{
+			UserID:       run.userID,
+			Content:      content,
+			NotionURL:    page.URL,
+			NotionPageID: notionPageID,
+			LastEditedAt: page.LastEditedTime,
+			LastSyncedAt: now,
+			SyncStatus:   domain.NotionPageSynced,
+			Markdown:     markdown,
+			Outline:      outline,
+		})
+		if err != nil {
+			return err
+		}
+		run.byNotionID[notionPageID] = created
+		run.report.Created++
+		return nil
+	}
+
+	// A restored page had its keywords pruned, so it counts as changed even
+	// if its content is the same as before the removal.
+	changed := current.Content != content || current.SyncStatus == domain.NotionPageRemoved
+	updated := *current
+	updated.Content = content
+	updated.Markdown = markdown
+	updated.Outline = outline
+	updated.NotionURL = page.URL
+	updated.LastEditedAt = page.LastEditedTime
+	updated.LastSyncedAt = now
+	updated.SyncStatus = domain.NotionPageSynced
+	updated.SyncError = ""
+	if _, err := s.repo.UpdateNotionPage(ctx, &updated); err != nil {
+		return err
+	}
+	run.byNotionID[notionPageID] = &updated
+
+	if changed {
+		run.changed = append(run.changed, current.ID)
+		run.report.Updated++
+	} else {
+		run.report.Unchanged++
+	}
+
+	return nil
+}
+
+// upToDate reports whether the stored page was fetched after the last edit of
+// page in notion, so it need not be fetched again.
+func upToDate(current *domain.NotionPage, page *notion.Page) bool {
+	return current.SyncStatus == domain.NotionPageSynced &&
+		current.Markdown != "" &&
+		current.NotionURL == page.URL &&
+		current.LastEditedAt.Equal(page.LastEditedTime) &&
+		current.LastSyncedAt.After(page.LastEditedTime.Add(editedTimePrecision))
+}
+
+// markRemoved marks the synced pages which notion did not return as removed.
+// They were deleted, archived or are no longer shared with the integration.
+// Pages uploaded by the client are left alone.
+func (s *NotionSyncService) markRemoved(ctx context.Context, run *notionSync) error {
+	for notionPageID, page := range run.byNotionID {
+		if run.seen[notionPageID] {
+			continue
+		}
+		if err := s.removePage(ctx, run, page); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *NotionSyncService) removePage(ctx context.Context, run *notionSync, page *domain.NotionPage) error {
+	if page.LastSyncedAt.IsZero() || page.SyncStatus == domain.NotionPageRemoved {
+		return nil
+	}
+
+	removed := *page
+	removed.SyncStatus = domain.NotionPageRemoved
+	removed.SyncError = ""
+	if _, err := s.repo.UpdateNotionPage(ctx, &removed); err != nil {
+		return err
+	}
+	run.byNotionID[page.NotionPageID] = &removed
+	run.removed = append(run.removed, page.ID)
+	run.report.Removed++
+
+	return nil
+}
+
+// SyncPage syncs a single page of the user which notion reported as changed,
+// or as deleted when deleted is set.
+func (s *NotionSyncService) SyncPage(
+	ctx context.Context,
+	userID uuid.UUID,
+	pageID string,
+	deleted bool,
+) (*domain.NotionSyncReport, error) {
+	notionPageID, err := uuid.Parse(pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock := s.locks.lock(userID)
+	defer unlock()
+
+	var run *notionSync
+	err = s.tokens.Do(ctx, userID, func(ctx context.Context, accessToken string) error {
+		existing, err := s.repo.FindAllNotionPagesByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		run = &notionSync{
+			userID:      userID,
+			accessToken: accessToken,
+			byNotionID:  make(map[uuid.UUID]*domain.NotionPage, len(existing)),
+			seen:        make(map[uuid.UUID]bool),
+			report:      &domain.NotionSyncReport{},
+		}
+		for _, page := range existing {
+			run.byNotionID[page.NotionPageID] = page
+		}
+		current, ok := run.byNotionID[notionPageID]
+
+		var page *notion.Page
+		if !deleted {
+			page, err = s.reader.GetPage(ctx, accessToken, pageID)
+			if notion.IsNotFound(err) {
+				// no longer shared with the integration
+				deleted = true
+			} else if err != nil {
+				return err
+			} else if page.Archived || page.InTrash {
+				deleted = true
+			}
+		}
+
+		if deleted {
+			if ok {
+				return s.removePage(ctx, run, current)
+			}
+			return nil
+		}
+
+		err = s.syncPage(ctx, run, page, true)
+		if errors.Is(err, ErrNotionUnauthorized) {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.reconcileMindMap(ctx, run); err != nil {
+		return nil, err
+	}
+
+	return run.report, nil
+}
+
+// Enqueue asks the worker to sync a single page of the user. It reports false
+// when the queue is full.
+func (s *NotionSyncService) Enqueue(userID uuid.UUID, pageID string, deleted bool) bool {
+	select {
+	case s.queue <- &pageSyncJob{userID: userID, pageID: pageID, deleted: deleted}:
+		return true
+	default:
+		return false
+	}
+}
+
+// RunQueue syncs the enqueued pages until ctx is done.
+func (s *NotionSyncService) RunQueue(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-s.queue:
+			report, err := s.SyncPage(ctx, job.userID, job.pageID, job.deleted)
+			if err != nil {
+				log.Printf("fail to sync notion page %s of user %s: %v", job.pageID, job.userID.String(), err)
+				continue
+			}
+			for _, failure := range report.Failures {
+				log.Printf("fail to sync notion page %s of user %s: %s",
+					failure.NotionPageID, job.userID.String(), failure.Error)
+			}
+		}
+	}
+}
+
+// readBlocks reads the children of blockID with their own children, down to
+// maxBlockDepth. Child pages are synced on their own, so their content is not
+// read.
+func (s *NotionSyncService) readBlocks(
+	ctx context.Context,
+	accessToken, blockID string,
+	depth int,
+) ([]*notion.BlockNode, error) {
+	blocks, err := s.reader.ListBlockChildren(ctx, accessToken, blockID)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]*notion.BlockNode, 0, len(blocks))
+	for _, block := range blocks {
+		node := &notion.BlockNode{Block: block}
+		nodes = append(nodes, node)
+
+		if !block.HasChildren || depth+1 >= maxBlockDepth ||
+			block.Type == "child_page" || block.Type == "child_database" {
+			continue
+		}
+		if node.Children, err = s.readBlocks(ctx, accessToken, block.ID, depth+1); err != nil {
+			return nil, err
+		}
+	}
+
+	return nodes, nil
+}
+
+// writeText writes the plain text of the blocks, one line per block and
+// indented by depth.
+func writeText(b *strings.Builder, nodes []*notion.BlockNode, depth int) {
+	for _, node := range nodes {
+		if text := notion.BlockText(node.Block); text != "" {
+			b.WriteString(strings.Repeat("  ", depth))
+			b.WriteString(text)
+			b.WriteString("\n")
+		}
+		writeText(b, node.Children, depth+1)
+	}
+}
+
+func toOutline(items []*notion.OutlineItem) []*domain.NotionOutlineItem {
+	if len(items) == 0 {
+		return nil
+	}
+
+	outline := make([]*domain.NotionOutlineItem, 0, len(items))
+	for _, item := range items {
+		outline = append(outline, &domain.NotionOutlineItem{
+			Level:    item.Level,
+			Text:     item.Text,
+			BlockID:  item.BlockID,
+			Children: toOutline(item.Children),
+		})
+	}
+	return outline
+}
+
+// SyncAll syncs every user whose notion connection is usable, one after the
+// other. Failures are logged and do not stop the others.
+func (s *NotionSyncService) SyncAll(ctx context.Context) {
+	users, err := s.users.ListUsers(ctx)
+	if err != nil {
+		log.Printf("fail to list users to sync: %v", err)
+		return
+	}
+
+	for _, user := range users {
+		if ctx.Err() != nil {
+			return
+		}
+		if user.Disabled || user.NotionDisconnected || user.AccessToken == "" {
+			continue
+		}
+
+		report, err := s.Sync(ctx, user.ID)
+		if err != nil {
+			log.Printf("fail to sync notion pages of user %s: %v", user.ID.String(), err)
+			continue
+		}
+		if report.Created+report.Updated+report.Removed+report.Failed > 0 {
+			log.Printf(
+				"synced notion pages of user %s: %d created, %d updated, %d removed, %d failed",
+				user.ID.String(), report.Created, report.Updated, report.Removed, report.Failed,
+			)
+		}
+	}
+}
+
+// RunScheduler syncs all users every interval until ctx is done.
+func (s *NotionSyncService) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.SyncAll(ctx)
+		}
+	}
+}
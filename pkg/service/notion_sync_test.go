@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/config"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/notion"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/repository"
+)
+
+// fakeNotionReader shares pages whose content is a single paragraph.
+type fakeNotionReader struct {
+	pages []*notion.Page
+}
+
+func (r *fakeNotionReader) Search(
+	ctx context.Context,
+	accessToken string,
+	params *notion.SearchParams,
+) (*notion.List[*notion.Page], error) {
+	return &notion.List[*notion.Page]{Results: r.pages}, nil
+}
+
+func (r *fakeNotionReader) GetPage(ctx context.Context, accessToken, pageID string) (*notion.Page, error) {
+	for _, page := range r.pages {
+		if page.ID == pageID {
+			return page, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *fakeNotionReader) ListBlockChildren(ctx context.Context, accessToken, blockID string) ([]*notion.Block, error) {
+	return []*notion.Block{{
+		Type:    "paragraph",
+		Content: json.RawMessage(`{"rich_text":[{"plain_text":"same content"}]}`),
+	}}, nil
+}
+
+func TestNotionSyncServiceRestoredPageIsChanged(t *testing.T) {
+	ctx := context.Background()
+	repos, err := repository.New(ctx, &config.StorageConfig{Driver: repository.DriverMemory}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := NewUserService(repos.User, repos.NotionPage, repos.MindMap, repos.APIKey, repos.UnitOfWork)
+	mindMap := NewMindMapService(repos.MindMap, repos.UnitOfWork)
+	reader := &fakeNotionReader{}
+	svc := NewNotionSyncService(repos.NotionPage, users, mindMap, reader, NewTokenManager(users, nil, nil))
+
+	userID, err := users.CreateUser(ctx, &domain.User{Nickname: "alice", AccessToken: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := &notion.Page{
+		ID:             "0123456789abcdef0123456789abcdef",
+		URL:            "https://www.notion.so/page",
+		LastEditedTime: time.Now().Add(-time.Hour).Truncate(time.Minute),
+	}
+
+	reader.pages = []*notion.Page{page}
+	if report, err := svc.Sync(ctx, userID); err != nil || report.Created != 1 {
+		t.Fatalf("first sync = %+v, %v, want one created page", report, err)
+	}
+
+	reader.pages = nil
+	if report, err := svc.Sync(ctx, userID); err != nil || report.Removed != 1 {
+		t.Fatalf("sync without the page = %+v, %v, want one removed page", report, err)
+	}
+
+	// The page comes back unedited, yet its pruned keywords need rebuilding.
+	reader.pages = []*notion.Page{page}
+	report, err := svc.Sync(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Updated != 1 || report.Unchanged != 0 {
+		t.Fatalf("restoring sync updated %d and left %d unchanged, want 1 and 0", report.Updated, report.Unchanged)
+	}
+
+	pages, err := repos.NotionPage.FindAllNotionPagesByUser(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 1 || pages[0].SyncStatus != domain.NotionPageSynced {
+		t.Fatalf("pages after the restore = %+v, want one synced page", pages)
+	}
+}
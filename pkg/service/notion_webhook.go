@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/notion"
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/repository"
+)
+
+// webhookDedupeTTL is how long a delivered event id is remembered. Notion
+// retries a failed delivery for a few hours at most.
+const webhookDedupeTTL = 24 * time.Hour
+
+// NotionWebhookService turns the page events notion pushes into syncs of the
+// pages of the users who shared them.
+type NotionWebhookService struct {
+	userRepo repository.UserRepository
+	sync     *NotionSyncService
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+func NewNotionWebhookService(
+	userRepo repository.UserRepository,
+	sync *NotionSyncService,
+) *NotionWebhookService {
+	return &NotionWebhookService{
+		userRepo: userRepo,
+		sync:     sync,
+		seen:     make(map[string]time.Time),
+	}
+}
+
+// Handle enqueues a sync of the page of the event for every user whose bot
+// can access it. A redelivered event is ignored. It returns how many syncs were
+// enqueued.
+func (s *NotionWebhookService) Handle(ctx context.Context, event *notion.WebhookEvent) (int, error) {
+	if event.Entity.Type != "page" {
+		return 0, nil
+	}
+
+	var deleted bool
+	switch event.Type {
+	case notion.EventPageCreated, notion.EventPageContentUpdated, notion.EventPagePropertiesUpdated,
+		notion.EventPageMoved, notion.EventPageUndeleted:
+	case notion.EventPageDeleted:
+		deleted = true
+	default:
+		return 0, nil
+	}
+
+	if !s.firstDelivery(event.ID) {
+		return 0, nil
+	}
+
+	queued := 0
+	for _, botID := range event.BotIDs() {
+		user, err := s.userRepo.FindUserByNotionBotID(ctx, botID)
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			// let notion deliver the event again
+			s.forget(event.ID)
+			return queued, err
+		}
+		if user.Disabled || user.NotionDisconnected {
+			continue
+		}
+
+		if !s.sync.Enqueue(user.ID, event.Entity.ID, deleted) {
+			log.Printf("notion sync queue is full, page %s of user %s waits for the scheduled sync",
+				event.Entity.ID, user.ID.String())
+			continue
+		}
+		queued++
+	}
+
+	return queued, nil
+}
+
+// firstDelivery records the event id and reports whether it was new.
+func (s *NotionWebhookService) firstDelivery(id string) bool {
+	if id == "" {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for k, at := range s.seen {
+		if now.Sub(at) > webhookDedupeTTL {
+			delete(s.seen, k)
+		}
+	}
+
+	if _, ok := s.seen[id]; ok {
+		return false
+	}
+	s.seen[id] = now
+
+	return true
+}
+
+func (s *NotionWebhookService) forget(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.seen, id)
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+
+	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/domain"
+)
+
+var (
+	// ErrNotionUnauthorized is returned by notion calls when notion rejects the
+	// access token, which makes the TokenManager refresh it.
+	ErrNotionUnauthorized = errors.New("notion rejected the access token")
+	// ErrRefreshRejected is returned by a TokenRefresher when notion refuses
+	// the refresh token, as opposed to the token endpoint being unreachable.
+	ErrRefreshRejected    = errors.New("notion rejected the refresh token")
+	ErrNotionDisconnected = errors.New("the notion connection was revoked, sign in with notion again")
+)
+
+type TokenRefresher interface {
+	RefreshToken(ctx context.Context, refreshToken string) (*domain.Token, error)
+}
+
+// SessionTokenUpdater hands refreshed tokens to the live sessions of a user.
+type SessionTokenUpdater interface {
+	UpdateToken(ctx context.Context, userID uuid.UUID, token *domain.Token) error
+}
+
+// TokenManager runs notion calls with the stored access token of a user and
+// refreshes the token when notion rejects it.
+type TokenManager struct {
+	users     *UserService
+	refresher TokenRefresher
+	sessions  SessionTokenUpdater
+	// locks serializes the refreshes of one user.
+	locks *userLocks
+}
+
+func NewTokenManager(
+	users *UserService,
+	refresher TokenRefresher,
+	sessions SessionTokenUpdater,
+) *TokenManager {
+	return &TokenManager{
+		users:     users,
+		refresher: refresher,
+		sessions:  sessions,
+		locks:     newUserLocks(),
+	}
+}
+
+// Do calls fn with the access token of the user. When fn fails with
+// ErrNotionUnauthorized the token is refreshed and fn is called once more.
+// ErrNotionDisconnected is returned when the user has to sign in again.
+func (m *TokenManager) Do(
+	ctx context.Context,
+	userID uuid.UUID,
+	fn func(ctx context.Context, accessToken string) error,
+) error {
+	user, err := m.users.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.NotionDisconnected {
+		return ErrNotionDisconnected
+	}
+
+	err = fn(ctx, user.AccessToken)
+	if !errors.Is(err, ErrNotionUnauthorized) {
+		return err
+	}
+
+	accessToken, err := m.refresh(ctx, userID, user.AccessToken)
+	if err != nil {
+		return err
+	}
+
+	err = fn(ctx, accessToken)
+	if errors.Is(err, ErrNotionUnauthorized) {
+		// Notion does not accept the token it has just issued, so the
+		// integration must have been removed from the workspace.
+		return m.disconnect(ctx, userID)
+	}
+
+	return err
+}
+
+// Connected reports whether the notion tokens of the user are still usable as
+// far as the server knows.
+func (m *TokenManager) Connected(ctx context.Context, userID uuid.UUID) (bool, error) {
+	user, err := m.users.GetUser(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return !user.NotionDisconnected, nil
+}
+
+// refresh replaces the rejected access token of the user and returns the new
+// one. Concurrent callers of the same user share a single refresh, since
+// notion rotates the refresh token on every use.
+func (m *TokenManager) refresh(ctx context.Context, userID uuid.UUID, rejected string) (string, error) {
+	unlock := m.locks.lock(userID)
+	defer unlock()
+
+	user, err := m.users.GetUser(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if user.NotionDisconnected {
+		return "", ErrNotionDisconnected
+	}
+	if user.AccessToken != rejected {
+		// refreshed by another call while this one waited for the lock
+		return user.AccessToken, nil
+	}
+	if user.RefreshToken == "" {
+		return "", m.disconnect(ctx, userID)
+	}
+
+	tok, err := m.refresher.RefreshToken(ctx, user.RefreshToken)
+	if errors.Is(err, ErrRefreshRejected) {
+		log.Printf("fail to refresh notion token of user %s: %v", userID.String(), err)
+		return "", m.disconnect(ctx, userID)
+	}
+	if err != nil {
+		return "", err
+	}
+	if tok.RefreshToken == "" {
+		tok.RefreshToken = user.RefreshToken
+	}
+
+	if err := m.users.UpdateTokens(ctx, userID, tok.AccessToken, tok.RefreshToken); err != nil {
+		return "", err
+	}
+	if err := m.sessions.UpdateToken(ctx, userID, tok); err != nil {
+		return "", err
+	}
+
+	return tok.AccessToken, nil
+}
+
+func (m *TokenManager) disconnect(ctx context.Context, userID uuid.UUID) error {
+	if err := m.users.SetNotionDisconnected(ctx, userID, true); err != nil {
+		return err
+	}
+	log.Printf("user %s is disconnected from notion", userID.String())
+
+	return ErrNotionDisconnected
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/google/uuid"
 
@@ -9,17 +11,36 @@ import (
 	"github.com/the-end-of-the-human-era-has-arrived/2025-oss-dev-competition-backend/pkg/repository"
 )
 
+var ErrUserDisabled = errors.New("the account is disabled")
+
 type UserService struct {
-	repo *repository.MemoryUserRepo
+	repo        repository.UserRepository
+	pageRepo    repository.NotionPageRepository
+	mindMapRepo repository.MindMapRepository
+	apiKeyRepo  repository.APIKeyRepository
+	uow         repository.UnitOfWork
 }
 
-func NewUserService(repo *repository.MemoryUserRepo) *UserService {
+func NewUserService(
+	repo repository.UserRepository,
+	pageRepo repository.NotionPageRepository,
+	mindMapRepo repository.MindMapRepository,
+	apiKeyRepo repository.APIKeyRepository,
+	uow repository.UnitOfWork,
+) *UserService {
 	return &UserService{
-		repo: repo,
+		repo:        repo,
+		pageRepo:    pageRepo,
+		mindMapRepo: mindMapRepo,
+		apiKeyRepo:  apiKeyRepo,
+		uow:         uow,
 	}
 }
 
 func (s *UserService) CreateUser(ctx context.Context, user *domain.User) (uuid.UUID, error) {
+	user.Role = domain.RoleUser
+	user.Disabled = false
+
 	user, err := s.repo.CreateUser(ctx, user)
 	if err != nil {
 		return uuid.Nil, err
@@ -27,27 +48,58 @@ func (s *UserService) CreateUser(ctx context.Context, user *domain.User) (uuid.U
 	return user.ID, nil
 }
 
+// SignIn returns the account of the notion user of user, creating it on the
+// first sign in. The tokens of an existing account are replaced by the new ones.
+func (s *UserService) SignIn(ctx context.Context, user *domain.User) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		existing, err := s.repo.FindUserByNotionUserID(ctx, user.NotionUserID)
+		if errors.Is(err, repository.ErrNotFound) {
+			id, err = s.CreateUser(ctx, user)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if existing.Disabled {
+			return ErrUserDisabled
+		}
+
+		existing.AccessToken = user.AccessToken
+		existing.RefreshToken = user.RefreshToken
+		existing.NotionDisconnected = false
+		if user.NotionBotID != "" {
+			existing.NotionBotID = user.NotionBotID
+		}
+		if _, err := s.repo.UpdateUser(ctx, existing); err != nil {
+			return err
+		}
+		id = existing.ID
+		return nil
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return id, nil
+}
+
 func (s *UserService) GetUser(ctx context.Context, id uuid.UUID) (*domain.User, error) {
 	return s.repo.FindUserByID(ctx, id)
 }
 
 func (s *UserService) UpdateNickname(ctx context.Context, id uuid.UUID, nickname string) error {
-	s.repo.BeginTransaction(ctx)
-	defer s.repo.Commit(ctx)
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		user, err := s.repo.FindUserByID(ctx, id)
+		if err != nil {
+			return err
+		}
 
-	user, err := s.repo.FindUserByID(ctx, id)
-	if err != nil {
-		s.repo.Abort(ctx)
-		return err
-	}
-
-	user.Nickname = nickname
+		user.Nickname = nickname
 
-	if _, err := s.repo.UpdateUser(ctx, user); err != nil {
-		s.repo.Abort(ctx)
+		_, err = s.repo.UpdateUser(ctx, user)
 		return err
-	}
-	return nil
+	})
 }
 
 func (s *UserService) UpdateTokens(
@@ -55,28 +107,178 @@ func (s *UserService) UpdateTokens(
 	id uuid.UUID,
 	accessToken, refreshToken string,
 ) error {
-	s.repo.BeginTransaction(ctx)
-	defer s.repo.Commit(ctx)
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		user, err := s.repo.FindUserByID(ctx, id)
+		if err != nil {
+			return err
+		}
 
+		user.AccessToken = accessToken
+		user.RefreshToken = refreshToken
+		user.NotionDisconnected = false
+
+		_, err = s.repo.UpdateUser(ctx, user)
+		return err
+	})
+}
+
+// DeleteUser deletes the user together with every notion page, keyword node
+// and keyword edge owned by the user in one unit of work.
+func (s *UserService) DeleteUser(
+	ctx context.Context,
+	id uuid.UUID,
+) (*domain.AccountDeletionReport, error) {
+	report := &domain.AccountDeletionReport{
+		UserID: id,
+	}
+
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		if _, err := s.repo.DeleteUserByID(ctx, id); err != nil {
+			return err
+		}
+
+		pages, err := s.pageRepo.FindAllNotionPagesByUser(ctx, id)
+		if err != nil {
+			return err
+		}
+		for _, p := range pages {
+			if _, err := s.pageRepo.DeleteNotionPageByID(ctx, p.ID); err != nil {
+				return err
+			}
+		}
+		report.NotionPages = len(pages)
+
+		edges, err := s.mindMapRepo.ListKeywordEdgeByUser(ctx, id)
+		if err != nil {
+			return err
+		}
+		deletedEdges, err := s.mindMapRepo.DeleteBulkKeywordEdges(ctx, domain.ExtractIDFromBulkEdges(edges))
+		if err != nil {
+			return err
+		}
+		report.KeywordEdges = len(deletedEdges)
+
+		nodes, err := s.mindMapRepo.ListKeywordNodeByUser(ctx, id)
+		if err != nil {
+			return err
+		}
+		deletedNodes, err := s.mindMapRepo.DeleteBulkKeywordNodes(ctx, domain.ExtractIDFromBulkNodes(nodes))
+		if err != nil {
+			return err
+		}
+		report.KeywordNodes = len(deletedNodes)
+
+		if report.APIKeys, err = s.apiKeyRepo.DeleteAPIKeysByUser(ctx, id); err != nil {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (s *UserService) ListUsers(ctx context.Context) ([]*domain.User, error) {
+	return s.repo.ListUsers(ctx)
+}
+
+// IsAdmin reports whether the user exists, is enabled and has the admin role.
+func (s *UserService) IsAdmin(ctx context.Context, id uuid.UUID) bool {
 	user, err := s.repo.FindUserByID(ctx, id)
 	if err != nil {
-		s.repo.Abort(ctx)
-		return err
+		return false
 	}
 
-	user.AccessToken = accessToken
-	user.RefreshToken = refreshToken
+	return user.IsAdmin()
+}
 
-	if _, err := s.repo.UpdateUser(ctx, user); err != nil {
-		s.repo.Abort(ctx)
-		return err
+func (s *UserService) SetRole(ctx context.Context, id uuid.UUID, role string) error {
+	if !domain.ValidRole(role) {
+		return fmt.Errorf("unknown role: %s", role)
 	}
-	return nil
+
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		user, err := s.repo.FindUserByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		user.Role = role
+
+		_, err = s.repo.UpdateUser(ctx, user)
+		return err
+	})
 }
 
-func (s *UserService) DeleteUser(ctx context.Context, id uuid.UUID) error {
-	if _, err := s.repo.DeleteUserByID(ctx, id); err != nil {
+func (s *UserService) SetDisabled(ctx context.Context, id uuid.UUID, disabled bool) error {
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		user, err := s.repo.FindUserByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		user.Disabled = disabled
+
+		_, err = s.repo.UpdateUser(ctx, user)
 		return err
+	})
+}
+
+func (s *UserService) SetNotionDisconnected(ctx context.Context, id uuid.UUID, disconnected bool) error {
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		user, err := s.repo.FindUserByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		user.NotionDisconnected = disconnected
+
+		_, err = s.repo.UpdateUser(ctx, user)
+		return err
+	})
+}
+
+// GetUsage counts the data stored for the user. Sessions are left to the
+// caller, which owns the session store.
+func (s *UserService) GetUsage(ctx context.Context, id uuid.UUID) (*domain.UserUsage, error) {
+	usage := &domain.UserUsage{
+		UserID: id,
+	}
+
+	err := s.uow.View(ctx, func(ctx context.Context) error {
+		if _, err := s.repo.FindUserByID(ctx, id); err != nil {
+			return err
+		}
+
+		pages, err := s.pageRepo.FindAllNotionPagesByUser(ctx, id)
+		if err != nil {
+			return err
+		}
+		usage.NotionPages = len(pages)
+		for _, p := range pages {
+			usage.ContentBytes += len(p.Content) + len(p.Summary)
+		}
+
+		nodes, err := s.mindMapRepo.ListKeywordNodeByUser(ctx, id)
+		if err != nil {
+			return err
+		}
+		usage.KeywordNodes = len(nodes)
+
+		edges, err := s.mindMapRepo.ListKeywordEdgeByUser(ctx, id)
+		if err != nil {
+			return err
+		}
+		usage.KeywordEdges = len(edges)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return nil
+
+	return usage, nil
 }